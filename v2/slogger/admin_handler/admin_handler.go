@@ -0,0 +1,332 @@
+// Copyright 2026 MongoDB, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package admin_handler provides an http.Handler for inspecting and
+// changing logging at runtime: log levels, flushing, log file rotation
+// and dumping retained logs.
+
+package admin_handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mongodb/slogger/v2/slogger"
+)
+
+// A Rotator is a log file that can be rotated or reopened, such as a
+// *rolling_file_appender.RollingFileAppender.
+type Rotator interface {
+	Rotate() error
+	Reopen() error
+}
+
+// A Retainer holds logs by category, such as a
+// *retaining_level_filter_appender.RetainingLevelFilterAppender.
+type Retainer interface {
+	AppendRetainedLogs(category string) []error
+}
+
+// Handler serves these endpoints, relative to where it is mounted:
+//
+//	GET    /levels                      levels set and registered loggers' effective levels
+//	GET    /levels/{prefix}             effective level for prefix
+//	PUT    /levels/{prefix}             set level for prefix, from {"level": "debug"}
+//	DELETE /levels/{prefix}             remove the level set for prefix
+//	POST   /flush                       flush every registered Logger
+//	POST   /rotate[/{name}]             rotate every, or the named, Rotator
+//	POST   /reopen[/{name}]             reopen every, or the named, Rotator
+//	POST   /retained/{name}/{category}  append a Retainer's logs for category
+//
+// The empty prefix, whose level applies to every prefix without a
+// more specific one, is written "*".  Responses are JSON.  Errors are
+// returned as {"error": "..."} or, for operations on several loggers
+// or files, {"errors": [...]}.
+//
+// To mount it on an existing mux under a path, strip the path:
+//
+//	mux.Handle("/debug/log/", http.StripPrefix("/debug/log", handler))
+type Handler struct {
+	levels *slogger.LevelRegistry
+
+	lock sync.RWMutex
+
+	// These fields are protected by lock
+	loggers   []*slogger.Logger
+	rotators  map[string]Rotator
+	retainers map[string]Retainer
+}
+
+func New(levels *slogger.LevelRegistry) *Handler {
+	return &Handler{
+		levels:    levels,
+		rotators:  make(map[string]Rotator),
+		retainers: make(map[string]Retainer),
+	}
+}
+
+// RegisterLogger adds logger to those listed under /levels and
+// flushed by /flush.
+func (self *Handler) RegisterLogger(logger *slogger.Logger) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.loggers = append(self.loggers, logger)
+}
+
+func (self *Handler) RegisterRotator(name string, rotator Rotator) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.rotators[name] = rotator
+}
+
+func (self *Handler) RegisterRetainer(name string, retainer Retainer) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.retainers[name] = retainer
+}
+
+type levelResponse struct {
+	Prefix string `json:"prefix"`
+	Level  string `json:"level"`
+	Set    bool   `json:"set"` // whether the level is set for prefix itself
+}
+
+type levelsResponse struct {
+	Levels  map[string]string `json:"levels"`
+	Loggers []levelResponse   `json:"loggers"`
+}
+
+type levelRequest struct {
+	Level string `json:"level"`
+}
+
+func (self *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(r.URL.Path, "/")
+	route, rest := path, ""
+	if i := strings.IndexByte(path, '/'); i >= 0 {
+		route, rest = path[:i], path[i+1:]
+	}
+
+	switch {
+	case route == "levels" && rest == "":
+		self.serveLevels(w, r)
+	case route == "levels":
+		self.serveLevel(w, r, rest)
+	case route == "flush" && rest == "":
+		self.serveFlush(w, r)
+	case route == "rotate":
+		self.serveRotate(w, r, rest, Rotator.Rotate)
+	case route == "reopen":
+		self.serveRotate(w, r, rest, Rotator.Reopen)
+	case route == "retained":
+		self.serveRetained(w, r, rest)
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("Unknown path: %s", r.URL.Path))
+	}
+}
+
+func (self *Handler) serveLevels(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	response := levelsResponse{
+		Levels:  make(map[string]string),
+		Loggers: []levelResponse{},
+	}
+	for prefix, level := range self.levels.Levels() {
+		response.Levels[displayPrefix(prefix)] = level.String()
+	}
+
+	seen := make(map[string]bool)
+	for _, logger := range self.registeredLoggers() {
+		if !seen[logger.Prefix] {
+			seen[logger.Prefix] = true
+			response.Loggers = append(response.Loggers, self.level(logger.Prefix))
+		}
+	}
+	sort.Slice(response.Loggers, func(i, j int) bool {
+		return response.Loggers[i].Prefix < response.Loggers[j].Prefix
+	})
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (self *Handler) serveLevel(w http.ResponseWriter, r *http.Request, prefix string) {
+	if prefix == "*" {
+		prefix = ""
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var request levelRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("Invalid request body: %v", err))
+			return
+		}
+
+		level, err := slogger.NewLevel(request.Level)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		self.levels.SetLevel(prefix, level)
+	case http.MethodDelete:
+		if prefix == "" {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("The level for * cannot be removed"))
+			return
+		}
+		self.levels.UnsetLevel(prefix)
+	default:
+		allowMethod(w, r, http.MethodGet, http.MethodPut, http.MethodDelete)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, self.level(strings.TrimSuffix(prefix, ".*")))
+}
+
+func (self *Handler) serveFlush(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	var errs []error
+	for _, logger := range self.registeredLoggers() {
+		for _, err := range logger.Flush() {
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	writeResult(w, errs)
+}
+
+func (self *Handler) serveRotate(w http.ResponseWriter, r *http.Request, name string, operation func(Rotator) error) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	self.lock.RLock()
+	rotators := make(map[string]Rotator, len(self.rotators))
+	for rotatorName, rotator := range self.rotators {
+		if name == "" || rotatorName == name {
+			rotators[rotatorName] = rotator
+		}
+	}
+	self.lock.RUnlock()
+
+	if name != "" && len(rotators) == 0 {
+		writeError(w, http.StatusNotFound, fmt.Errorf("No rotator named %q", name))
+		return
+	}
+
+	var errs []error
+	for rotatorName, rotator := range rotators {
+		if err := operation(rotator); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", rotatorName, err))
+		}
+	}
+	writeResult(w, errs)
+}
+
+func (self *Handler) serveRetained(w http.ResponseWriter, r *http.Request, rest string) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	i := strings.IndexByte(rest, '/')
+	if i <= 0 || i == len(rest)-1 {
+		writeError(w, http.StatusNotFound, fmt.Errorf("Expected /retained/{name}/{category}"))
+		return
+	}
+	name, category := rest[:i], rest[i+1:]
+
+	self.lock.RLock()
+	retainer, found := self.retainers[name]
+	self.lock.RUnlock()
+
+	if !found {
+		writeError(w, http.StatusNotFound, fmt.Errorf("No retainer named %q", name))
+		return
+	}
+
+	writeResult(w, retainer.AppendRetainedLogs(category))
+}
+
+func (self *Handler) registeredLoggers() []*slogger.Logger {
+	self.lock.RLock()
+	defer self.lock.RUnlock()
+	return append([]*slogger.Logger{}, self.loggers...)
+}
+
+func (self *Handler) level(prefix string) levelResponse {
+	_, set := self.levels.Levels()[prefix]
+	return levelResponse{
+		Prefix: displayPrefix(prefix),
+		Level:  self.levels.EffectiveLevel(prefix).String(),
+		Set:    set,
+	}
+}
+
+func displayPrefix(prefix string) string {
+	if prefix == "" {
+		return "*"
+	}
+	return prefix
+}
+
+// allowMethod writes a 405 response and returns false unless r's
+// method is one of methods.
+func allowMethod(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, method := range methods {
+		if r.Method == method {
+			return true
+		}
+	}
+
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("Method %s not allowed", r.Method))
+	return false
+}
+
+// writeResult writes {"ok": true}, or the errors with a 500 status.
+func writeResult(w http.ResponseWriter, errs []error) {
+	var messages []string
+	for _, err := range errs {
+		if err != nil {
+			messages = append(messages, err.Error())
+		}
+	}
+
+	if len(messages) == 0 {
+		writeJSON(w, http.StatusOK, map[string]bool{"ok": true})
+		return
+	}
+	writeJSON(w, http.StatusInternalServerError, map[string][]string{"errors": messages})
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,221 @@
+// Copyright 2026 MongoDB, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin_handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mongodb/slogger/v2/slogger"
+	"github.com/mongodb/slogger/v2/slogger/retaining_level_filter_appender"
+)
+
+func TestLevels(test *testing.T) {
+	levels := slogger.NewLevelRegistry(slogger.INFO)
+	levels.SetLevel("db", slogger.WARN)
+
+	handler := New(levels)
+	handler.RegisterLogger(&slogger.Logger{Prefix: "db.query"})
+	handler.RegisterLogger(&slogger.Logger{Prefix: "http"})
+
+	var response levelsResponse
+	assertStatus(test, serve(handler, "GET", "/levels", "", &response), http.StatusOK)
+
+	if len(response.Levels) != 2 || response.Levels["*"] != "info" || response.Levels["db"] != "warn" {
+		test.Errorf("Unexpected levels: %v", response.Levels)
+	}
+
+	expected := []levelResponse{
+		{Prefix: "db.query", Level: "warn", Set: false},
+		{Prefix: "http", Level: "info", Set: false},
+	}
+	if len(response.Loggers) != len(expected) {
+		test.Fatalf("Expected loggers %v. Received: %v", expected, response.Loggers)
+	}
+	for i := range expected {
+		if response.Loggers[i] != expected[i] {
+			test.Errorf("Expected logger %v. Received: %v", expected[i], response.Loggers[i])
+		}
+	}
+}
+
+func TestSetAndUnsetLevel(test *testing.T) {
+	levels := slogger.NewLevelRegistry(slogger.INFO)
+	handler := New(levels)
+
+	var response levelResponse
+	assertStatus(test, serve(handler, "PUT", "/levels/db", `{"level": "debug"}`, &response), http.StatusOK)
+	if response != (levelResponse{Prefix: "db", Level: "debug", Set: true}) {
+		test.Errorf("Unexpected response: %v", response)
+	}
+	if levels.EffectiveLevel("db.query") != slogger.DEBUG {
+		test.Errorf("Expected db.query to be at debug. Received: %v", levels.EffectiveLevel("db.query"))
+	}
+
+	assertStatus(test, serve(handler, "GET", "/levels/db.query", "", &response), http.StatusOK)
+	if response != (levelResponse{Prefix: "db.query", Level: "debug", Set: false}) {
+		test.Errorf("Unexpected response: %v", response)
+	}
+
+	assertStatus(test, serve(handler, "PUT", "/levels/*", `{"level": "error"}`, &response), http.StatusOK)
+	if response != (levelResponse{Prefix: "*", Level: "error", Set: true}) {
+		test.Errorf("Unexpected response: %v", response)
+	}
+
+	assertStatus(test, serve(handler, "DELETE", "/levels/db", "", &response), http.StatusOK)
+	if response != (levelResponse{Prefix: "db", Level: "error", Set: false}) {
+		test.Errorf("Unexpected response: %v", response)
+	}
+
+	assertStatus(test, serve(handler, "DELETE", "/levels/*", "", nil), http.StatusBadRequest)
+	assertStatus(test, serve(handler, "PUT", "/levels/db", `{"level": "loud"}`, nil), http.StatusBadRequest)
+	assertStatus(test, serve(handler, "PUT", "/levels/db", `debug`, nil), http.StatusBadRequest)
+	assertStatus(test, serve(handler, "POST", "/levels/db", "", nil), http.StatusMethodNotAllowed)
+	assertStatus(test, serve(handler, "GET", "/nowhere", "", nil), http.StatusNotFound)
+}
+
+func TestFlush(test *testing.T) {
+	appender := &recordingAppender{}
+	handler := New(slogger.NewLevelRegistry(slogger.INFO))
+	handler.RegisterLogger(&slogger.Logger{Appenders: []slogger.Appender{appender}})
+
+	assertStatus(test, serve(handler, "POST", "/flush", "", nil), http.StatusOK)
+	if appender.flushes != 1 {
+		test.Errorf("Expected 1 flush. Received: %d", appender.flushes)
+	}
+
+	appender.err = errors.New("disk full")
+	var response map[string][]string
+	assertStatus(test, serve(handler, "POST", "/flush", "", &response), http.StatusInternalServerError)
+	if len(response["errors"]) != 1 || response["errors"][0] != "disk full" {
+		test.Errorf("Unexpected response: %v", response)
+	}
+
+	assertStatus(test, serve(handler, "GET", "/flush", "", nil), http.StatusMethodNotAllowed)
+}
+
+func TestRotateAndReopen(test *testing.T) {
+	first, second := &recordingRotator{}, &recordingRotator{}
+	handler := New(slogger.NewLevelRegistry(slogger.INFO))
+	handler.RegisterRotator("first", first)
+	handler.RegisterRotator("second", second)
+
+	assertStatus(test, serve(handler, "POST", "/rotate", "", nil), http.StatusOK)
+	assertStatus(test, serve(handler, "POST", "/reopen/second", "", nil), http.StatusOK)
+	assertStatus(test, serve(handler, "POST", "/rotate/third", "", nil), http.StatusNotFound)
+
+	if first.rotations != 1 || first.reopens != 0 {
+		test.Errorf("Expected first to be rotated once. Received: %+v", first)
+	}
+	if second.rotations != 1 || second.reopens != 1 {
+		test.Errorf("Expected second to be rotated and reopened once. Received: %+v", second)
+	}
+}
+
+func TestRetained(test *testing.T) {
+	buffer := new(bytes.Buffer)
+	appender := retaining_level_filter_appender.New(
+		"category",
+		10,
+		slogger.INFO,
+		slogger.NewStringAppender(buffer),
+	)
+	handler := New(slogger.NewLevelRegistry(slogger.TRACE))
+	handler.RegisterRetainer("main", appender)
+
+	logger := &slogger.Logger{Appenders: []slogger.Appender{appender}}
+	context := slogger.NewContext()
+	context.Add("category", "query")
+	logger.LogfWithContext(slogger.DEBUG, "Retained message", context)
+
+	if strings.Contains(buffer.String(), "Retained message") {
+		test.Fatalf("Expected the debug message to be retained. Received: %s", buffer.String())
+	}
+
+	assertStatus(test, serve(handler, "POST", "/retained/main/query", "", nil), http.StatusOK)
+	if !strings.Contains(buffer.String(), "Retained message") {
+		test.Errorf("Expected the retained message to be appended. Received: %s", buffer.String())
+	}
+
+	assertStatus(test, serve(handler, "POST", "/retained/other/query", "", nil), http.StatusNotFound)
+	assertStatus(test, serve(handler, "POST", "/retained/main", "", nil), http.StatusNotFound)
+}
+
+func TestMountedUnderPrefix(test *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/debug/log/", http.StripPrefix("/debug/log", New(slogger.NewLevelRegistry(slogger.WARN))))
+
+	var response levelResponse
+	assertStatus(test, serve(mux, "GET", "/debug/log/levels/*", "", &response), http.StatusOK)
+	if response.Level != "warn" {
+		test.Errorf("Expected the root level to be warn. Received: %v", response)
+	}
+}
+
+// serve sends a request to handler and decodes the JSON response body
+// into response unless it is nil.
+func serve(handler http.Handler, method, path, body string, response interface{}) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(method, path, strings.NewReader(body)))
+
+	if response != nil {
+		json.Unmarshal(recorder.Body.Bytes(), response)
+	}
+	return recorder
+}
+
+func assertStatus(test *testing.T, recorder *httptest.ResponseRecorder, expected int) {
+	test.Helper()
+	if recorder.Code != expected {
+		test.Errorf("Expected status %d. Received: %d %s", expected, recorder.Code, recorder.Body.String())
+	}
+	if contentType := recorder.Header().Get("Content-Type"); contentType != "application/json" {
+		test.Errorf("Expected a JSON response. Received Content-Type: %s", contentType)
+	}
+}
+
+type recordingAppender struct {
+	flushes int
+	err     error
+}
+
+func (self *recordingAppender) Append(log *slogger.Log) error {
+	return nil
+}
+
+func (self *recordingAppender) Flush() error {
+	self.flushes++
+	return self.err
+}
+
+type recordingRotator struct {
+	rotations int
+	reopens   int
+}
+
+func (self *recordingRotator) Rotate() error {
+	self.rotations++
+	return nil
+}
+
+func (self *recordingRotator) Reopen() error {
+	self.reopens++
+	return nil
+}
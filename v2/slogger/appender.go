@@ -18,6 +18,8 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"strings"
+	"sync"
 )
 
 type Appender interface {
@@ -25,6 +27,50 @@ type Appender interface {
 	Flush() error
 }
 
+// LevelEnabler is implemented by Appenders that can tell in advance
+// whether they would append a Log at the given level.
+type LevelEnabler interface {
+	Enabled(level Level) bool
+}
+
+// AppenderEnabled reports whether appender could append a Log at the
+// given level.  Appenders that do not implement LevelEnabler are
+// assumed to accept every level.
+func AppenderEnabled(appender Appender, level Level) bool {
+	if enabler, ok := appender.(LevelEnabler); ok {
+		return enabler.Enabled(level)
+	}
+	return true
+}
+
+// BatchAppender is implemented by Appenders that can append several
+// Logs at once more cheaply than appending them one at a time, for
+// example with a single write.  AppendBatch must not retain logs after
+// it returns.
+type BatchAppender interface {
+	AppendBatch(logs []*Log) error
+}
+
+// AppendBatch appends logs to appender, as a single batch if appender
+// is a BatchAppender and otherwise one at a time.  Every log is
+// appended even if some fail, and the errors are returned.
+func AppendBatch(appender Appender, logs []*Log) []error {
+	if batchAppender, ok := appender.(BatchAppender); ok {
+		if err := batchAppender.AppendBatch(logs); err != nil {
+			return []error{err}
+		}
+		return nil
+	}
+
+	var errs []error
+	for _, log := range logs {
+		if err := appender.Append(log); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
 var formatLogFunc = FormatLog
 
 func GetFormatLogFunc() func(log *Log) string {
@@ -40,6 +86,51 @@ func SetFormatLogFunc(f func(log *Log) string) {
 
 }
 
+// A Formatter renders a Log as the text written by an Appender.
+// Appenders that carry a Formatter fall back to the function set by
+// SetFormatLogFunc when their Formatter is nil.
+type Formatter interface {
+	Format(log *Log) string
+}
+
+// FormatterSetter is implemented by Appenders whose Formatter can be
+// changed after they are created.  Appenders that wrap another
+// Appender pass the Formatter on to it.  SetFormatter reports whether
+// the Formatter was set, which it is not when a wrapped Appender is
+// not a FormatterSetter.
+type FormatterSetter interface {
+	SetFormatter(formatter Formatter) bool
+}
+
+// SetAppenderFormatter sets appender's Formatter and reports whether
+// it was set.  It is not set when appender, or an Appender it wraps,
+// is not a FormatterSetter.
+func SetAppenderFormatter(appender Appender, formatter Formatter) bool {
+	setter, ok := appender.(FormatterSetter)
+	return ok && setter.SetFormatter(formatter)
+}
+
+// FormatterFunc adapts a function such as FormatLog or FormatLogJSON
+// to the Formatter interface.
+type FormatterFunc func(log *Log) string
+
+func (f FormatterFunc) Format(log *Log) string {
+	return f(log)
+}
+
+// FormatWith formats log with formatter, or with the global format
+// function when formatter is nil.  A Log that has been preformatted
+// is not formatted again.
+func FormatWith(formatter Formatter, log *Log) string {
+	if log.hasPreformatted {
+		return log.preformatted
+	}
+	if formatter == nil {
+		return GetFormatLogFunc()(log)
+	}
+	return formatter.Format(log)
+}
+
 func formatLog(log *Log, timePart string) string {
 
 	errorCodeStr := ""
@@ -47,9 +138,15 @@ func formatLog(log *Log, timePart string) string {
 		errorCodeStr += fmt.Sprintf("[%v] ", log.ErrorCode)
 	}
 
-	return fmt.Sprintf("%v [%v.%v] [%v:%v:%d] %v%v\n",
+	traceStr := ""
+	if log.TraceID != "" || log.SpanID != "" {
+		traceStr = fmt.Sprintf("[trace:%v span:%v] ", log.TraceID, log.SpanID)
+	}
+
+	return fmt.Sprintf("%v [%v.%v] [%v:%v:%d] %v%v%v\n",
 		timePart, log.Prefix, log.Level.Type(),
 		log.Filename, log.FuncName, log.Line,
+		traceStr,
 		errorCodeStr,
 		log.Message())
 }
@@ -87,6 +184,16 @@ func FormatLogWithTimezone(log *Log) string {
 	)
 }
 
+// formatBatch formats each of logs with formatter and concatenates
+// them.
+func formatBatch(formatter Formatter, logs []*Log) string {
+	var msgs strings.Builder
+	for _, log := range logs {
+		msgs.WriteString(FormatWith(formatter, log))
+	}
+	return msgs.String()
+}
+
 func FormatLog(log *Log) string {
 	year, month, day := log.Timestamp.Date()
 	hour, min, sec := log.Timestamp.Clock()
@@ -109,8 +216,13 @@ type FileAppender struct {
 }
 
 func (self FileAppender) Append(log *Log) error {
-	f := GetFormatLogFunc()
-	_, err := self.WriteString(f(log))
+	_, err := self.WriteString(FormatWith(nil, log))
+	return err
+}
+
+// AppendBatch writes logs with a single WriteString.
+func (self FileAppender) AppendBatch(logs []*Log) error {
+	_, err := self.WriteString(formatBatch(nil, logs))
 	return err
 }
 
@@ -135,6 +247,38 @@ func DevNullAppender() (*FileAppender, error) {
 	return &FileAppender{devNull}, nil
 }
 
+// FormattingFileAppender is a FileAppender that renders logs with its
+// own Formatter rather than the global format function.
+type FormattingFileAppender struct {
+	FileAppender
+	formatter formatterField
+}
+
+// NewFileAppenderWithFormatter returns a FormattingFileAppender that
+// writes to writer.  A nil formatter means use GetFormatLogFunc().
+func NewFileAppenderWithFormatter(writer StringWriter, formatter Formatter) *FormattingFileAppender {
+	appender := &FormattingFileAppender{FileAppender: FileAppender{writer}}
+	appender.formatter.set(formatter)
+	return appender
+}
+
+func (self *FormattingFileAppender) Append(log *Log) error {
+	_, err := self.WriteString(FormatWith(self.formatter.get(), log))
+	return err
+}
+
+// AppendBatch writes logs with a single WriteString.
+func (self *FormattingFileAppender) AppendBatch(logs []*Log) error {
+	_, err := self.WriteString(formatBatch(self.formatter.get(), logs))
+	return err
+}
+
+// SetFormatter may be called while logs are being appended.
+func (self *FormattingFileAppender) SetFormatter(formatter Formatter) bool {
+	self.formatter.set(formatter)
+	return true
+}
+
 type StringAppender struct {
 	*bytes.Buffer
 }
@@ -144,8 +288,12 @@ func NewStringAppender(buffer *bytes.Buffer) *StringAppender {
 }
 
 func (self StringAppender) Append(log *Log) error {
-	f := GetFormatLogFunc()
-	_, err := self.WriteString(f(log))
+	_, err := self.WriteString(FormatWith(nil, log))
+	return err
+}
+
+func (self StringAppender) AppendBatch(logs []*Log) error {
+	_, err := self.WriteString(formatBatch(nil, logs))
 	return err
 }
 
@@ -153,12 +301,67 @@ func (self StringAppender) Flush() error {
 	return nil
 }
 
+// FormattingStringAppender is a StringAppender that renders logs with
+// its own Formatter rather than the global format function.
+type FormattingStringAppender struct {
+	StringAppender
+	formatter formatterField
+}
+
+// NewStringAppenderWithFormatter returns a FormattingStringAppender
+// that writes to buffer.  A nil formatter means use
+// GetFormatLogFunc().
+func NewStringAppenderWithFormatter(buffer *bytes.Buffer, formatter Formatter) *FormattingStringAppender {
+	appender := &FormattingStringAppender{StringAppender: StringAppender{buffer}}
+	appender.formatter.set(formatter)
+	return appender
+}
+
+func (self *FormattingStringAppender) Append(log *Log) error {
+	_, err := self.WriteString(FormatWith(self.formatter.get(), log))
+	return err
+}
+
+func (self *FormattingStringAppender) AppendBatch(logs []*Log) error {
+	_, err := self.WriteString(formatBatch(self.formatter.get(), logs))
+	return err
+}
+
+// SetFormatter may be called while logs are being appended.
+func (self *FormattingStringAppender) SetFormatter(formatter Formatter) bool {
+	self.formatter.set(formatter)
+	return true
+}
+
+// formatterField holds a Formatter that can be replaced while logs are
+// being formatted with it.
+type formatterField struct {
+	lock      sync.RWMutex
+	formatter Formatter // nil means use GetFormatLogFunc()
+}
+
+func (self *formatterField) get() Formatter {
+	self.lock.RLock()
+	defer self.lock.RUnlock()
+	return self.formatter
+}
+
+func (self *formatterField) set(formatter Formatter) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.formatter = formatter
+}
+
 // Return true if the log should be passed to the underlying
 // `Appender`
 type Filter func(log *Log) bool
 type FilterAppender struct {
 	Appender Appender
 	Filter   Filter
+
+	// set by LevelFilter so that Enabled can be answered without a Log
+	threshold    Level
+	hasThreshold bool
 }
 
 func (self *FilterAppender) Append(log *Log) error {
@@ -173,13 +376,31 @@ func (self *FilterAppender) Flush() error {
 	return self.Appender.Flush()
 }
 
+// Enabled is false for levels below a LevelFilter's threshold.  An
+// arbitrary Filter cannot be evaluated without a Log, so otherwise
+// this defers to the underlying Appender.
+func (self *FilterAppender) Enabled(level Level) bool {
+	if self.hasThreshold && level < self.threshold {
+		return false
+	}
+	return AppenderEnabled(self.Appender, level)
+}
+
+// SetFormatter sets the underlying Appender's Formatter, if it has
+// one.
+func (self *FilterAppender) SetFormatter(formatter Formatter) bool {
+	return SetAppenderFormatter(self.Appender, formatter)
+}
+
 func LevelFilter(threshold Level, appender Appender) *FilterAppender {
 	filterFunc := func(log *Log) bool {
 		return log.Level >= threshold
 	}
 
 	return &FilterAppender{
-		Appender: appender,
-		Filter:   filterFunc,
+		Appender:     appender,
+		Filter:       filterFunc,
+		threshold:    threshold,
+		hasThreshold: true,
 	}
 }
@@ -18,69 +18,423 @@
 package async_appender
 
 import (
+	"context"
 	"fmt"
+	"hash/fnv"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/mongodb/slogger/v2/slogger"
 )
 
 type AsyncAppender struct {
 	Appender   slogger.Appender
-	appendCh   chan *slogger.Log
-	flushCh    chan (chan bool)
 	errHandler func(error)
+
+	overflowPolicy     OverflowPolicy
+	dropReportInterval time.Duration
+	dropped            dropCounters
+
+	maxBatchSize int
+	maxLinger    time.Duration
+
+	preformat    bool
+	preformatter slogger.Formatter
+
+	// workers pass logs to Appender.  When shardKey is empty they
+	// share one appendCh, otherwise each has its own.
+	workers  []*worker
+	shardKey string
+
+	// closed is protected by lock.  Append holds a read lock while
+	// checking it and registering with sending, and gives up sending
+	// once closeCh is closed, so the workers wait for sending before
+	// draining and nothing is sent after they drain.  Blocking sends
+	// are not made under the lock, so that Close never waits for them.
+	closed  bool
+	lock    sync.RWMutex
+	sending sync.WaitGroup
+
+	closeCh  chan struct{} // closed by Close to start draining
+	abortCh  chan struct{} // closed by Close when its deadline is hit
+	doneCh   chan struct{} // closed when every worker has returned
+	closeErr error         // set before doneCh is closed
 }
 
-func New(appender slogger.Appender, channelCapacity int, errHandler func(error)) *AsyncAppender {
+type asyncAppenderBuilder struct {
+	appender           slogger.Appender
+	channelCapacity    int
+	errHandler         func(error)
+	overflowPolicy     OverflowPolicy
+	dropReportInterval time.Duration
+	maxBatchSize       int
+	maxLinger          time.Duration
+	numWorkers         int
+	shardKey           string
+	preformat          bool
+	preformatter       slogger.Formatter
+}
+
+// NewBuilder returns a new asyncAppenderBuilder.  You can directly
+// call Build() to create a new AsyncAppender, or configure additional
+// options first.
+//
+// appender is the Appender that logs are passed to from a separate
+// goroutine.  channelCapacity is the number of logs that can be
+// queued for it.  errHandler, if not nil, is called with errors
+// returned by appender.
+//
+// By default Append blocks while the queue is full (see Block).
+func NewBuilder(appender slogger.Appender, channelCapacity int, errHandler func(error)) *asyncAppenderBuilder {
+	return &asyncAppenderBuilder{
+		appender:           appender,
+		channelCapacity:    channelCapacity,
+		errHandler:         errHandler,
+		overflowPolicy:     Block,
+		dropReportInterval: 10 * time.Second,
+		maxBatchSize:       1,
+		maxLinger:          0,
+		numWorkers:         1,
+		shardKey:           "",
+		preformat:          false,
+		preformatter:       nil,
+	}
+}
+
+// WithOverflowPolicy sets what Append does when the queue is full.
+// Policies that drop logs report how many were dropped, per level, in
+// a warning Log appended at most once per dropReportInterval.  A
+// dropReportInterval that is not positive is replaced by the default
+// of 10s.
+func (b *asyncAppenderBuilder) WithOverflowPolicy(policy OverflowPolicy, dropReportInterval time.Duration) *asyncAppenderBuilder {
+	b.overflowPolicy = policy
+	b.dropReportInterval = dropReportInterval
+	return b
+}
+
+// WithBatching makes the AsyncAppender collect logs into batches of
+// up to maxBatchSize before passing them to the wrapped Appender.  A
+// batch that is not full is passed on once its first log has waited
+// for maxLinger, or when the AsyncAppender is flushed.
+//
+// Batches are passed to a wrapped slogger.BatchAppender with a single
+// AppendBatch call, and to other Appenders one log at a time.
+func (b *asyncAppenderBuilder) WithBatching(maxBatchSize int, maxLinger time.Duration) *asyncAppenderBuilder {
+	b.maxBatchSize = maxBatchSize
+	b.maxLinger = maxLinger
+	return b
+}
+
+// WithWorkers passes logs to the wrapped Appender from numWorkers
+// goroutines, which must then be safe for concurrent use.
+//
+// If shardKey is empty, the workers take logs from a single queue in
+// parallel and logs may be appended out of order.  Otherwise each
+// worker has its own queue of channelCapacity logs, and logs are
+// assigned to workers by the value of shardKey in their Context, so
+// that logs with the same value are appended in order.  Logs without
+// the key all go to the same worker.
+//
+// Batching, if enabled, is done by each worker separately.
+func (b *asyncAppenderBuilder) WithWorkers(numWorkers int, shardKey string) *asyncAppenderBuilder {
+	b.numWorkers = numWorkers
+	b.shardKey = shardKey
+	return b
+}
+
+// WithPreformatting formats each log with formatter, or with
+// slogger.GetFormatLogFunc() if formatter is nil, on the goroutine
+// that appends it.  The wrapped Appender then writes the preformatted
+// text (see slogger.Log.Preformat) rather than formatting the log
+// itself, so its own Formatter is not used.  Appenders that do not
+// format logs as text, such as slog_bridge's HandlerAppender, ignore
+// the preformatted text.
+func (b *asyncAppenderBuilder) WithPreformatting(formatter slogger.Formatter) *asyncAppenderBuilder {
+	b.preformat = true
+	b.preformatter = formatter
+	return b
+}
+
+func (b *asyncAppenderBuilder) Build() *AsyncAppender {
+	numWorkers := b.numWorkers
+	if numWorkers < 1 {
+		numWorkers = 1
+	}
+
+	dropReportInterval := b.dropReportInterval
+	if dropReportInterval <= 0 {
+		dropReportInterval = 10 * time.Second
+	}
+
 	asyncAppender := &AsyncAppender{
-		Appender:   appender,
-		appendCh:   make(chan *slogger.Log, channelCapacity),
-		flushCh:    make(chan (chan bool)),
-		errHandler: errHandler,
+		Appender:           b.appender,
+		errHandler:         b.errHandler,
+		overflowPolicy:     b.overflowPolicy,
+		dropReportInterval: dropReportInterval,
+		maxBatchSize:       b.maxBatchSize,
+		maxLinger:          b.maxLinger,
+		workers:            make([]*worker, numWorkers),
+		shardKey:           b.shardKey,
+		preformat:          b.preformat,
+		preformatter:       b.preformatter,
+		closeCh:            make(chan struct{}),
+		abortCh:            make(chan struct{}),
+		doneCh:             make(chan struct{}),
 	}
 
-	go asyncAppender.listenForAppends()
+	appendCh := make(chan *slogger.Log, b.channelCapacity)
+	for i := range asyncAppender.workers {
+		if b.shardKey != "" && i > 0 {
+			appendCh = make(chan *slogger.Log, b.channelCapacity)
+		}
+		asyncAppender.workers[i] = &worker{
+			appender: asyncAppender,
+			appendCh: appendCh,
+			flushCh:  make(chan (chan bool)),
+		}
+	}
+
+	waitGroup := &sync.WaitGroup{}
+	for i := range asyncAppender.workers {
+		waitGroup.Add(1)
+		go func(w *worker, reportsDrops bool) {
+			defer waitGroup.Done()
+			w.listenForAppends(reportsDrops)
+		}(asyncAppender.workers[i], i == 0)
+	}
+
+	go func() {
+		waitGroup.Wait()
+		asyncAppender.finishClose()
+	}()
 
 	return asyncAppender
 }
 
+// New creates a new AsyncAppender that blocks while its queue is full.
+//
+// This is equivalent to calling NewBuilder().Build()
+func New(appender slogger.Appender, channelCapacity int, errHandler func(error)) *AsyncAppender {
+	return NewBuilder(appender, channelCapacity, errHandler).Build()
+}
+
 func (self *AsyncAppender) Append(log *slogger.Log) error {
-	// Interpolate log message arguments now to prevent data races
-	// when an argument to a log message is modified soon after the
-	// logging call.
+	self.lock.RLock()
+	if self.closed {
+		self.lock.RUnlock()
+		return ClosedError{}
+	}
+	self.sending.Add(1)
+	self.lock.RUnlock()
+	defer self.sending.Done()
+
+	// Interpolate log message arguments and copy the context now to
+	// prevent data races when an argument to a log message or the
+	// context is modified soon after the logging call.
 	logCopy := *log
-	logCopy.MessageFmt = fmt.Sprintf(logCopy.MessageFmt, logCopy.Args...)
+	logCopy.MessageFmt = strings.ReplaceAll(fmt.Sprintf(logCopy.MessageFmt, logCopy.Args...), "%", "%%")
 	logCopy.Args = []interface{}{}
+	logCopy.Context = log.Context.Clone()
+
+	if self.preformat {
+		logCopy.Preformat(self.preformatter)
+	}
 
+	appendCh := self.workerFor(&logCopy).appendCh
 	select {
-	case self.appendCh <- &logCopy:
-		// nothing else to do
+	case appendCh <- &logCopy:
+		return nil
 	default:
-		// channel is full. log a warning
-		self.appendCh <- self.fullWarningLog()
-		self.appendCh <- &logCopy
+		return self.overflow(appendCh, &logCopy)
 	}
-	return nil
 }
 
+// Flush returns once every log appended before it was called has been
+// passed to the wrapped Appender, by every worker, and the wrapped
+// Appender has been flushed.
+//
+// Flush gives up and returns a ClosedError if Close is called while it
+// waits.
 func (self *AsyncAppender) Flush() error {
+	// Workers sharing a queue only reply true once it is empty and
+	// they are not appending, so asking every one in turn covers logs
+	// taken from the queue by any of them.
 	replyCh := make(chan bool)
-	self.flushCh <- replyCh
-	for !(<-replyCh) {
-		self.flushCh <- replyCh
+	for _, worker := range self.workers {
+		for {
+			select {
+			case worker.flushCh <- replyCh:
+			case <-self.closeCh:
+				return ClosedError{}
+			}
+
+			if <-replyCh {
+				break
+			}
+		}
 	}
 	return nil
 }
 
+// Close stops accepting new logs, appends the logs already queued to
+// the wrapped Appender, flushes it, and closes it if it is an
+// io.Closer.  Append and Flush return a ClosedError once Close has been
+// called, including those blocked waiting for room in the queue.
+//
+// If ctx is done before the queued logs are appended, Close returns a
+// DrainTimeoutError with the number of logs that were dropped, and the
+// wrapped Appender is neither flushed nor closed.  Otherwise Close
+// returns the error from flushing or closing the wrapped Appender.
+func (self *AsyncAppender) Close(ctx context.Context) error {
+	self.lock.Lock()
+	if self.closed {
+		self.lock.Unlock()
+		return ClosedError{}
+	}
+	self.closed = true
+	close(self.closeCh)
+	self.lock.Unlock()
+
+	select {
+	case <-self.doneCh:
+		return self.closeErr
+	case <-ctx.Done():
+		close(self.abortCh)
+		return DrainTimeoutError{Dropped: self.queued(), Err: ctx.Err()}
+	}
+}
+
+// CloseWithTimeout calls Close with a context that is done after
+// timeout.
+func (self *AsyncAppender) CloseWithTimeout(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return self.Close(ctx)
+}
+
+// Enabled defers to the wrapped Appender.
+func (self *AsyncAppender) Enabled(level slogger.Level) bool {
+	return slogger.AppenderEnabled(self.Appender, level)
+}
+
+// SetFormatter sets the wrapped Appender's Formatter, if it has one.
+// Logs preformatted by the AsyncAppender (see WithPreformatting) keep
+// the Formatter they were preformatted with.
+func (self *AsyncAppender) SetFormatter(formatter slogger.Formatter) bool {
+	return slogger.SetAppenderFormatter(self.Appender, formatter)
+}
+
+// workerFor returns the worker whose queue log should be sent to.
+func (self *AsyncAppender) workerFor(log *slogger.Log) *worker {
+	if self.shardKey == "" || len(self.workers) == 1 {
+		return self.workers[0]
+	}
+
+	if log.Context == nil {
+		return self.workers[0]
+	}
+
+	value, found := log.Context.Get(self.shardKey)
+	if !found {
+		return self.workers[0]
+	}
+
+	hash := fnv.New32a()
+	fmt.Fprint(hash, value)
+	return self.workers[hash.Sum32()%uint32(len(self.workers))]
+}
+
+// queued returns the number of logs not yet passed to the wrapped
+// Appender.
+func (self *AsyncAppender) queued() int {
+	queued := 0
+	for i, worker := range self.workers {
+		if self.shardKey != "" || i == 0 {
+			queued += len(worker.appendCh)
+		}
+		queued += int(atomic.LoadInt64(&worker.batched))
+	}
+	return queued
+}
+
 func (self *AsyncAppender) appendToSubAppender(log *slogger.Log) {
 	if err := self.Appender.Append(log); err != nil && self.errHandler != nil {
 		self.errHandler(err)
 	}
 }
 
-func (self *AsyncAppender) fullWarningLog() *slogger.Log {
+// overflow handles a log that did not fit in appendCh according to
+// the overflow policy.  It returns a ClosedError if Close is called
+// while it blocks.
+func (self *AsyncAppender) overflow(appendCh chan *slogger.Log, log *slogger.Log) error {
+	switch self.overflowPolicy.kind {
+	case dropNewest:
+		self.dropped.add(log.Level)
+	case dropOldest:
+		for {
+			select {
+			case appendCh <- log:
+				return nil
+			default:
+				// make room by removing the oldest log, unless a
+				// worker just did
+				select {
+				case oldest := <-appendCh:
+					self.dropped.add(oldest.Level)
+				default:
+				}
+			}
+		}
+	case dropBelowLevel:
+		if log.Level < self.overflowPolicy.level {
+			self.dropped.add(log.Level)
+			return nil
+		}
+		return self.send(appendCh, log)
+	default:
+		// channel is full. log a warning
+		if err := self.send(appendCh, self.fullWarningLog(appendCh)); err != nil {
+			return err
+		}
+		return self.send(appendCh, log)
+	}
+	return nil
+}
+
+// send blocks until log is queued on appendCh, or returns a
+// ClosedError if Close is called first.
+func (self *AsyncAppender) send(appendCh chan *slogger.Log, log *slogger.Log) error {
+	select {
+	case appendCh <- log:
+		return nil
+	case <-self.closeCh:
+		return ClosedError{}
+	}
+}
+
+// dropsLog returns a warning Log with the number of logs dropped by
+// the overflow policy since the last report, or nil if none were.
+func (self *AsyncAppender) dropsLog() *slogger.Log {
+	total, byLevel := self.dropped.takeCounts()
+	if total == 0 {
+		return nil
+	}
+
+	return internalWarningLog(
+		"This AsyncAppender's append channel was full and %d logs were dropped (%s). The channelCapacity is %d.",
+		total,
+		byLevel,
+		cap(self.workers[0].appendCh),
+	)
+}
+
+func (self *AsyncAppender) fullWarningLog(appendCh chan *slogger.Log) *slogger.Log {
 	return internalWarningLog(
 		"This AsyncAppender's append channel is full. The channelCapacity is %d.  You may want to increase it next time.",
-		cap(self.appendCh),
+		cap(appendCh),
 	)
 }
 
@@ -88,31 +442,189 @@ func internalWarningLog(messageFmt string, args ...interface{}) *slogger.Log {
 	return slogger.SimpleLog("AsyncAppender", slogger.WARN, slogger.NoErrorCode, 3, messageFmt, args...)
 }
 
+// finishClose runs once every worker has returned.  Unless Close's
+// deadline was hit, it reports any drops not yet reported, then
+// flushes and closes the wrapped Appender.
+func (self *AsyncAppender) finishClose() {
+	defer close(self.doneCh)
+
+	select {
+	case <-self.abortCh:
+		return
+	default:
+	}
+
+	if log := self.dropsLog(); log != nil {
+		self.appendToSubAppender(log)
+	}
+
+	if err := self.Appender.Flush(); err != nil {
+		self.closeErr = err
+	}
+
+	if closer, ok := self.Appender.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			self.closeErr = err
+		}
+	}
+}
+
+// A worker passes logs from its appendCh to the wrapped Appender.
+type worker struct {
+	appender *AsyncAppender
+	appendCh chan *slogger.Log
+	flushCh  chan (chan bool)
+
+	// These fields are only used by the worker's goroutine, except
+	// batched which is read atomically by Close.
+	batch       []*slogger.Log
+	batched     int64
+	lingerTimer *time.Timer
+}
+
+// appendLog passes log to the wrapped Appender, or adds it to the
+// current batch if batching.  It returns true if any logs were passed
+// to the wrapped Appender.
+func (self *worker) appendLog(log *slogger.Log) bool {
+	if self.appender.maxBatchSize <= 1 {
+		self.appender.appendToSubAppender(log)
+		return true
+	}
+
+	self.batch = append(self.batch, log)
+	atomic.StoreInt64(&self.batched, int64(len(self.batch)))
+	if len(self.batch) >= self.appender.maxBatchSize {
+		self.appendBatch()
+		return true
+	}
+
+	if len(self.batch) == 1 {
+		self.lingerTimer.Reset(self.appender.maxLinger)
+	}
+	return false
+}
+
+// appendBatch passes the current batch, if any, to the wrapped
+// Appender.
+func (self *worker) appendBatch() {
+	if len(self.batch) == 0 {
+		return
+	}
+
+	if !self.lingerTimer.Stop() {
+		select {
+		case <-self.lingerTimer.C:
+		default:
+		}
+	}
+
+	errs := slogger.AppendBatch(self.appender.Appender, self.batch)
+	self.batch = make([]*slogger.Log, 0, self.appender.maxBatchSize)
+	atomic.StoreInt64(&self.batched, 0)
+
+	if errHandler := self.appender.errHandler; errHandler != nil {
+		for _, err := range errs {
+			errHandler(err)
+		}
+	}
+}
+
+// reportDrops appends a warning Log with the number of logs dropped
+// by the overflow policy since the last report, if any were.
+func (self *worker) reportDrops() {
+	if log := self.appender.dropsLog(); log != nil {
+		self.appendLog(log)
+	}
+}
+
 // listenForAppends consumes appendCh and flushCh.  It consumes Logs
 // coming down the appendCh, flushing the underlying Appender when
 // necessary and the appendCh is empty.  It will reply to flushCh
 // messages (via the given flushReplyCh) after flushing (or if nothing
 // has ever been logged), increasing the chance that it will be able
-// to reply true.
-func (self *AsyncAppender) listenForAppends() {
+// to reply true.  It returns once closeCh is closed and the remaining
+// logs have been drained.  Only one worker reportsDrops.
+func (self *worker) listenForAppends(reportsDrops bool) {
+	closeCh := self.appender.closeCh
+
+	var reportCh <-chan time.Time // nil, and never ready, if nothing can be dropped
+	if reportsDrops && self.appender.overflowPolicy.kind != block {
+		ticker := time.NewTicker(self.appender.dropReportInterval)
+		defer ticker.Stop()
+		reportCh = ticker.C
+	}
+
+	var lingerCh <-chan time.Time // nil, and never ready, if not batching
+	if self.appender.maxBatchSize > 1 {
+		self.lingerTimer = time.NewTimer(self.appender.maxLinger)
+		self.lingerTimer.Stop()
+		defer self.lingerTimer.Stop()
+		lingerCh = self.lingerTimer.C
+	}
+
 	needsFlush := false
 	for {
+		select {
+		case <-closeCh:
+			self.drain()
+			return
+		default:
+		}
+
 		if needsFlush {
 			select {
 			case log := <-self.appendCh:
-				self.appendToSubAppender(log)
+				self.appendLog(log)
+			case <-reportCh:
+				self.reportDrops()
 			default:
-				self.Appender.Flush()
+				self.appender.Appender.Flush()
 				needsFlush = false
 			}
 		} else {
 			select {
 			case log := <-self.appendCh:
-				self.appendToSubAppender(log)
+				needsFlush = self.appendLog(log)
+			case <-lingerCh:
+				self.appendBatch()
 				needsFlush = true
 			case flushReplyCh := <-self.flushCh:
+				if len(self.batch) > 0 {
+					self.appendBatch()
+					self.appender.Appender.Flush()
+				}
 				flushReplyCh <- (len(self.appendCh) <= 0)
+			case <-reportCh:
+				self.reportDrops()
+				needsFlush = true
+			case <-closeCh:
+				self.drain()
+				return
 			}
 		}
 	}
 }
+
+// drain appends the remaining queued logs and the current batch
+// unless Close's deadline is hit first.  It first waits for Appends in
+// progress, which give up once closeCh is closed, so that no log is
+// queued after it returns.
+func (self *worker) drain() {
+	self.appender.sending.Wait()
+
+	for {
+		select {
+		case <-self.appender.abortCh:
+			return
+		default:
+		}
+
+		select {
+		case log := <-self.appendCh:
+			self.appendLog(log)
+		default:
+			self.appendBatch()
+			return
+		}
+	}
+}
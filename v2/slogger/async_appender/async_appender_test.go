@@ -16,6 +16,8 @@ package async_appender
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -25,6 +27,7 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/mongodb/slogger/v2/slogger"
 	. "github.com/mongodb/slogger/v2/slogger/test_util"
@@ -117,6 +120,512 @@ func TestConcurrentLog(test *testing.T) {
 	}
 }
 
+func TestSnapshot(test *testing.T) {
+	sub := &blockingAppender{release: make(chan struct{})}
+	appender := New(sub, 10, nil)
+	logger := &slogger.Logger{Appenders: []slogger.Appender{appender}}
+
+	ctxt := slogger.NewContext()
+	ctxt.Add("state", "before")
+	_, errs := logger.LogfWithContext(slogger.WARN, "100%% of %v", ctxt, "nodes")
+	AssertNoErrors(test, errs)
+
+	// modifying the context after the call does not affect the log
+	ctxt.Add("state", "after")
+	close(sub.release)
+	AssertNoErrors(test, logger.Flush())
+
+	sub.lock.Lock()
+	defer sub.lock.Unlock()
+	if len(sub.logs) != 1 {
+		test.Fatalf("Expected 1 log. Received: %d", len(sub.logs))
+	}
+	if message := sub.logs[0].Message(); message != "100% of nodes" {
+		test.Errorf("Expected the interpolated message. Received: %q", message)
+	}
+	if state, _ := sub.logs[0].Context.Get("state"); state != "before" {
+		test.Errorf("Expected the context as it was when logged. Received: %v", state)
+	}
+}
+
+func TestPreformatting(test *testing.T) {
+	buffer := new(bytes.Buffer)
+	sub := slogger.NewStringAppenderWithFormatter(buffer, slogger.FormatterFunc(slogger.FormatLogJSON))
+	appender := NewBuilder(sub, 10, nil).
+		WithPreformatting(slogger.FormatterFunc(slogger.FormatLogfmt)).
+		Build()
+	logger := &slogger.Logger{Prefix: "async", Appenders: []slogger.Appender{appender}}
+
+	_, errs := logger.Logf(slogger.WARN, "Preformatted")
+	AssertNoErrors(test, errs)
+	AssertNoErrors(test, logger.Flush())
+
+	// the wrapped appender writes the logfmt text rather than JSON
+	if !strings.Contains(buffer.String(), "prefix=async") || !strings.HasSuffix(buffer.String(), "msg=Preformatted\n") {
+		test.Errorf("Expected logfmt output. Received: %s", buffer.String())
+	}
+}
+
+func TestEnabled(test *testing.T) {
+	appender := New(slogger.LevelFilter(slogger.INFO, slogger.NewStringAppender(new(bytes.Buffer))), 16, nil)
+
+	if appender.Enabled(slogger.DEBUG) || !appender.Enabled(slogger.INFO) {
+		test.Errorf("Expected Enabled to defer to the wrapped appender")
+	}
+}
+
+func TestCloseDrains(test *testing.T) {
+	sub := &blockingAppender{}
+	appender := New(sub, 100, nil)
+	logger := &slogger.Logger{Appenders: []slogger.Appender{appender}}
+
+	for i := 0; i < 50; i++ {
+		_, errs := logger.Logf(slogger.WARN, "%d", i)
+		AssertNoErrors(test, errs)
+	}
+
+	if err := appender.CloseWithTimeout(5 * time.Second); err != nil {
+		test.Fatalf("Close returned an error: %v", err)
+	}
+
+	if sub.appended() != 50 || !sub.flushed || !sub.closed {
+		test.Errorf("Expected all logs appended, then a flush and close. Appended: %d Flushed: %v Closed: %v",
+			sub.appended(), sub.flushed, sub.closed)
+	}
+
+	if _, errs := logger.Logf(slogger.WARN, "late"); len(errs) != 1 {
+		test.Errorf("Expected an error appending after Close")
+	}
+
+	if err := appender.Flush(); !IsClosedError(err) {
+		test.Errorf("Expected a ClosedError flushing after Close. Received: %v", err)
+	}
+
+	if err := appender.CloseWithTimeout(time.Second); !IsClosedError(err) {
+		test.Errorf("Expected a ClosedError closing twice. Received: %v", err)
+	}
+}
+
+func TestCloseDeadline(test *testing.T) {
+	sub := &blockingAppender{release: make(chan struct{})}
+	appender := New(sub, 100, nil)
+
+	for i := 0; i < 10; i++ {
+		if err := appender.Append(slogger.SimpleLog("", slogger.WARN, slogger.NoErrorCode, 1, "%d", i)); err != nil {
+			test.Fatalf("Append returned an error: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := appender.Close(ctx)
+
+	var drainErr DrainTimeoutError
+	if !errors.As(err, &drainErr) || !errors.Is(err, context.DeadlineExceeded) {
+		test.Fatalf("Expected a DrainTimeoutError. Received: %v", err)
+	}
+
+	// one log is stuck in the sub appender
+	if drainErr.Dropped != 9 {
+		test.Errorf("Expected 9 dropped logs. Received: %d", drainErr.Dropped)
+	}
+
+	close(sub.release)
+	select {
+	case <-appender.doneCh:
+	case <-time.After(5 * time.Second):
+		test.Fatal("Expected the listening goroutine to exit")
+	}
+
+	if sub.flushed || sub.closed {
+		test.Errorf("Expected no flush or close after the deadline")
+	}
+}
+
+func TestCloseWhileAppendBlocked(test *testing.T) {
+	sub := &blockingAppender{release: make(chan struct{})}
+	appender := New(sub, 2, nil)
+	defer close(sub.release)
+	fillQueue(test, appender)
+
+	// the queue is full, so this Append blocks, as does Flush
+	appendErrCh := make(chan error, 1)
+	go func() {
+		appendErrCh <- appender.Append(slogger.SimpleLog("", slogger.WARN, slogger.NoErrorCode, 1, "3"))
+	}()
+	flushErrCh := make(chan error, 1)
+	go func() {
+		flushErrCh <- appender.Flush()
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	closeErrCh := make(chan error, 1)
+	go func() {
+		closeErrCh <- appender.CloseWithTimeout(100 * time.Millisecond)
+	}()
+
+	select {
+	case err := <-closeErrCh:
+		if !IsDrainTimeoutError(err) {
+			test.Errorf("Expected a DrainTimeoutError. Received: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		test.Fatal("Expected Close to return after its deadline")
+	}
+
+	for _, errCh := range []chan error{appendErrCh, flushErrCh} {
+		select {
+		case err := <-errCh:
+			if !IsClosedError(err) {
+				test.Errorf("Expected a ClosedError. Received: %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			test.Fatal("Expected the blocked call to return once closed")
+		}
+	}
+}
+
+func TestDropNewest(test *testing.T) {
+	sub := &blockingAppender{release: make(chan struct{})}
+	appender := NewBuilder(sub, 2, nil).
+		WithOverflowPolicy(DropNewest, 10*time.Millisecond).
+		Build()
+
+	fillQueue(test, appender)
+	appendLog(test, appender, slogger.DEBUG, "3")
+	appendLog(test, appender, slogger.WARN, "4")
+	appendLog(test, appender, slogger.WARN, "5")
+	close(sub.release)
+
+	waitUntil(test, func() bool { return sub.appended() == 4 })
+	assertMessages(test, sub, "0", "1", "2",
+		"This AsyncAppender's append channel was full and 3 logs were dropped (debug: 1, warn: 2). The channelCapacity is 2.")
+	if err := appender.CloseWithTimeout(5 * time.Second); err != nil {
+		test.Fatalf("Close returned an error: %v", err)
+	}
+}
+
+func TestDropOldest(test *testing.T) {
+	sub := &blockingAppender{release: make(chan struct{})}
+	appender := NewBuilder(sub, 2, nil).
+		WithOverflowPolicy(DropOldest, time.Hour).
+		Build()
+
+	fillQueue(test, appender)
+	appendLog(test, appender, slogger.INFO, "3")
+	appendLog(test, appender, slogger.ERROR, "4")
+	close(sub.release)
+
+	// drops are reported when draining, even before the interval
+	if err := appender.CloseWithTimeout(5 * time.Second); err != nil {
+		test.Fatalf("Close returned an error: %v", err)
+	}
+	assertMessages(test, sub, "0", "3", "4",
+		"This AsyncAppender's append channel was full and 2 logs were dropped (warn: 2). The channelCapacity is 2.")
+}
+
+func TestNonPositiveDropReportInterval(test *testing.T) {
+	sub := &blockingAppender{release: make(chan struct{})}
+	appender := NewBuilder(sub, 2, nil).
+		WithOverflowPolicy(DropNewest, 0).
+		Build()
+
+	fillQueue(test, appender)
+	appendLog(test, appender, slogger.WARN, "3")
+	close(sub.release)
+
+	if err := appender.CloseWithTimeout(5 * time.Second); err != nil {
+		test.Fatalf("Close returned an error: %v", err)
+	}
+	assertMessages(test, sub, "0", "1", "2",
+		"This AsyncAppender's append channel was full and 1 logs were dropped (warn: 1). The channelCapacity is 2.")
+}
+
+func TestDropBelowLevel(test *testing.T) {
+	sub := &blockingAppender{release: make(chan struct{})}
+	appender := NewBuilder(sub, 2, nil).
+		WithOverflowPolicy(DropBelowLevel(slogger.WARN), time.Hour).
+		Build()
+
+	fillQueue(test, appender)
+	appendLog(test, appender, slogger.DEBUG, "3")
+	appendLog(test, appender, slogger.INFO, "4")
+
+	appended := make(chan struct{})
+	go func() {
+		appendLog(test, appender, slogger.ERROR, "5")
+		close(appended)
+	}()
+
+	select {
+	case <-appended:
+		test.Fatal("Expected an ERROR log to block while the queue is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(sub.release)
+	<-appended
+	if err := appender.CloseWithTimeout(5 * time.Second); err != nil {
+		test.Fatalf("Close returned an error: %v", err)
+	}
+
+	assertMessages(test, sub, "0", "1", "2", "5",
+		"This AsyncAppender's append channel was full and 2 logs were dropped (debug: 1, info: 1). The channelCapacity is 2.")
+}
+
+func TestBatching(test *testing.T) {
+	sub := &batchingAppender{}
+	appender := NewBuilder(sub, 100, nil).
+		WithBatching(3, time.Hour).
+		Build()
+
+	for i := 0; i < 4; i++ {
+		appendLog(test, appender, slogger.WARN, strconv.Itoa(i))
+	}
+	waitUntil(test, func() bool { return len(sub.batchSizes()) == 1 })
+
+	// the partial batch is delivered by Flush rather than waiting
+	if err := appender.Flush(); err != nil {
+		test.Fatalf("Flush returned an error: %v", err)
+	}
+	if sizes := sub.batchSizes(); len(sizes) != 2 || sizes[0] != 3 || sizes[1] != 1 {
+		test.Errorf("Expected batches of 3 and 1. Received: %v", sizes)
+	}
+}
+
+func TestBatchLinger(test *testing.T) {
+	sub := &batchingAppender{}
+	appender := NewBuilder(sub, 100, nil).
+		WithBatching(100, 10*time.Millisecond).
+		Build()
+
+	appendLog(test, appender, slogger.WARN, "0")
+	appendLog(test, appender, slogger.WARN, "1")
+	waitUntil(test, func() bool { return len(sub.batchSizes()) == 1 })
+
+	if sizes := sub.batchSizes(); sizes[0] != 2 {
+		test.Errorf("Expected a batch of 2. Received: %v", sizes)
+	}
+}
+
+func TestBatchingPlainAppender(test *testing.T) {
+	sub := &blockingAppender{}
+	appender := NewBuilder(sub, 100, nil).
+		WithBatching(10, time.Hour).
+		Build()
+
+	appendLog(test, appender, slogger.WARN, "0")
+	appendLog(test, appender, slogger.WARN, "1")
+	if err := appender.CloseWithTimeout(5 * time.Second); err != nil {
+		test.Fatalf("Close returned an error: %v", err)
+	}
+
+	assertMessages(test, sub, "0", "1")
+}
+
+func TestShardedWorkers(test *testing.T) {
+	sub := &shardRecordingAppender{messages: make(map[string][]string)}
+	appender := NewBuilder(sub, 10, nil).
+		WithWorkers(4, "conn").
+		Build()
+	logger := &slogger.Logger{Appenders: []slogger.Appender{appender}}
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(conn string) {
+			defer wg.Done()
+			connLogger := logger.With("conn", conn)
+			for j := 0; j < 100; j++ {
+				_, errs := connLogger.Logf(slogger.WARN, "%d", j)
+				AssertNoErrors(test, errs)
+			}
+		}(fmt.Sprint("conn", i))
+	}
+	wg.Wait()
+
+	AssertNoErrors(test, logger.Flush())
+
+	sub.lock.Lock()
+	defer sub.lock.Unlock()
+	if len(sub.messages) != 8 {
+		test.Fatalf("Expected logs from 8 connections. Received: %d", len(sub.messages))
+	}
+	for conn, messages := range sub.messages {
+		if len(messages) != 100 {
+			test.Errorf("Expected 100 logs from %s. Received: %d", conn, len(messages))
+			continue
+		}
+		for j, message := range messages {
+			if message != strconv.Itoa(j) {
+				test.Errorf("Expected logs from %s in order. Received: %v", conn, messages)
+				break
+			}
+		}
+	}
+}
+
+func TestParallelWorkers(test *testing.T) {
+	sub := &blockingAppender{release: make(chan struct{})}
+	appender := NewBuilder(sub, 10, nil).
+		WithWorkers(3, "").
+		Build()
+
+	for i := 0; i < 3; i++ {
+		appendLog(test, appender, slogger.WARN, strconv.Itoa(i))
+	}
+
+	// every worker takes a log while the sub appender blocks
+	waitUntil(test, func() bool { return len(appender.workers[0].appendCh) == 0 })
+
+	close(sub.release)
+	if err := appender.Flush(); err != nil {
+		test.Fatalf("Flush returned an error: %v", err)
+	}
+	if sub.appended() != 3 {
+		test.Errorf("Expected Flush to wait for every worker. Appended: %d", sub.appended())
+	}
+}
+
+// fillQueue appends WARN logs "0", "1" and "2" to an appender with a
+// channelCapacity of 2 whose sub appender blocks, so that "0" is
+// being appended and "1" and "2" are queued.
+func fillQueue(test *testing.T, appender *AsyncAppender) {
+	appendLog(test, appender, slogger.WARN, "0")
+	waitUntil(test, func() bool { return len(appender.workers[0].appendCh) == 0 })
+	appendLog(test, appender, slogger.WARN, "1")
+	appendLog(test, appender, slogger.WARN, "2")
+}
+
+func appendLog(test *testing.T, appender *AsyncAppender, level slogger.Level, message string) {
+	if err := appender.Append(slogger.SimpleLog("", level, slogger.NoErrorCode, 1, message)); err != nil {
+		test.Errorf("Append returned an error: %v", err)
+	}
+}
+
+func assertMessages(test *testing.T, sub *blockingAppender, expected ...string) {
+	test.Helper()
+	actual := sub.appendedMessages()
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		test.Errorf("Expected messages %q. Received: %q", expected, actual)
+	}
+}
+
+func waitUntil(test *testing.T, condition func() bool) {
+	test.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			test.Fatal("Timed out waiting for condition")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// blockingAppender counts logs, blocking each Append until release is
+// closed if release is non-nil.
+type blockingAppender struct {
+	release  chan struct{}
+	count    int
+	logs     []*slogger.Log
+	messages []string
+	flushed  bool
+	closed   bool
+	lock     sync.Mutex
+}
+
+func (self *blockingAppender) Append(log *slogger.Log) error {
+	if self.release != nil {
+		<-self.release
+	}
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.count++
+	self.logs = append(self.logs, log)
+	self.messages = append(self.messages, log.Message())
+	return nil
+}
+
+func (self *blockingAppender) appended() int {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	return self.count
+}
+
+func (self *blockingAppender) appendedMessages() []string {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	return append([]string{}, self.messages...)
+}
+
+func (self *blockingAppender) Flush() error {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.flushed = true
+	return nil
+}
+
+func (self *blockingAppender) Close() error {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.closed = true
+	return nil
+}
+
+// batchingAppender records the size of each batch appended to it.
+type batchingAppender struct {
+	sizes []int
+	lock  sync.Mutex
+}
+
+func (self *batchingAppender) Append(log *slogger.Log) error {
+	return self.AppendBatch([]*slogger.Log{log})
+}
+
+func (self *batchingAppender) AppendBatch(logs []*slogger.Log) error {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.sizes = append(self.sizes, len(logs))
+	return nil
+}
+
+func (self *batchingAppender) batchSizes() []int {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	return append([]int{}, self.sizes...)
+}
+
+func (self *batchingAppender) Flush() error {
+	return nil
+}
+
+// shardRecordingAppender records messages by the "conn" value in
+// their Context, ignoring logs without one.
+type shardRecordingAppender struct {
+	messages map[string][]string
+	lock     sync.Mutex
+}
+
+func (self *shardRecordingAppender) Append(log *slogger.Log) error {
+	if log.Context == nil {
+		return nil
+	}
+	conn, found := log.Context.Get("conn")
+	if !found {
+		return nil
+	}
+
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	key := fmt.Sprint(conn)
+	self.messages[key] = append(self.messages[key], log.Message())
+	return nil
+}
+
+func (self *shardRecordingAppender) Flush() error {
+	return nil
+}
+
 func assertCurrentLogContains(test *testing.T, expected string, appender *AsyncAppender) {
 	stringAppender, ok := appender.Appender.(*slogger.StringAppender)
 	if !ok {
@@ -0,0 +1,52 @@
+// Copyright 2026 MongoDB, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package async_appender
+
+import (
+	"fmt"
+)
+
+type ClosedError struct{}
+
+func (ClosedError) Error() string {
+	return "async_appender: AsyncAppender is closed"
+}
+
+func IsClosedError(err error) bool {
+	_, ok := err.(ClosedError)
+	return ok
+}
+
+type DrainTimeoutError struct {
+	Dropped int
+	Err     error
+}
+
+func (self DrainTimeoutError) Error() string {
+	return fmt.Sprintf(
+		"async_appender: Dropped %d logs while closing: %s",
+		self.Dropped,
+		self.Err.Error(),
+	)
+}
+
+func (self DrainTimeoutError) Unwrap() error {
+	return self.Err
+}
+
+func IsDrainTimeoutError(err error) bool {
+	_, ok := err.(DrainTimeoutError)
+	return ok
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 MongoDB, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package async_appender
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/mongodb/slogger/v2/slogger"
+)
+
+type overflowKind int
+
+const (
+	block overflowKind = iota
+	dropNewest
+	dropOldest
+	dropBelowLevel
+)
+
+// An OverflowPolicy decides what AsyncAppender.Append does when the
+// AsyncAppender's queue is full.
+type OverflowPolicy struct {
+	kind  overflowKind
+	level slogger.Level // for dropBelowLevel
+}
+
+var (
+	// Block queues a warning that the queue is full, then blocks
+	// until there is room for the log.
+	Block = OverflowPolicy{kind: block}
+
+	// DropNewest drops the log being appended.
+	DropNewest = OverflowPolicy{kind: dropNewest}
+
+	// DropOldest drops the oldest queued log to make room.
+	DropOldest = OverflowPolicy{kind: dropOldest}
+)
+
+// DropBelowLevel drops the log being appended if its level is below
+// level, and otherwise blocks until there is room for it.
+func DropBelowLevel(level slogger.Level) OverflowPolicy {
+	return OverflowPolicy{kind: dropBelowLevel, level: level}
+}
+
+func (self OverflowPolicy) String() string {
+	switch self.kind {
+	case dropNewest:
+		return "drop_newest"
+	case dropOldest:
+		return "drop_oldest"
+	case dropBelowLevel:
+		return "drop_below_" + self.level.String()
+	default:
+		return "block"
+	}
+}
+
+// dropCounters counts dropped logs per level
+type dropCounters [slogger.OFF + 1]uint64
+
+func (self *dropCounters) add(level slogger.Level) {
+	if level > slogger.OFF {
+		level = slogger.OFF
+	}
+	atomic.AddUint64(&self[level], 1)
+}
+
+// takeCounts resets the counters, returning the total and a
+// description of the counts per level.
+func (self *dropCounters) takeCounts() (total uint64, byLevel string) {
+	counts := make([]string, 0, len(self))
+	for level := range self {
+		if n := atomic.SwapUint64(&self[level], 0); n > 0 {
+			total += n
+			counts = append(counts, fmt.Sprintf("%v: %d", slogger.Level(level), n))
+		}
+	}
+	return total, strings.Join(counts, ", ")
+}
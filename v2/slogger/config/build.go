@@ -0,0 +1,377 @@
+// Copyright 2026 MongoDB, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mongodb/slogger/v2/slogger"
+	"github.com/mongodb/slogger/v2/slogger/async_appender"
+	"github.com/mongodb/slogger/v2/slogger/network_appender"
+	"github.com/mongodb/slogger/v2/slogger/retaining_level_filter_appender"
+	"github.com/mongodb/slogger/v2/slogger/rolling_file_appender"
+	"github.com/mongodb/slogger/v2/slogger/syslog_appender"
+)
+
+// asyncCloseTimeout bounds how long closing a replaced AsyncAppender
+// waits for its queued logs to be appended
+const asyncCloseTimeout = 10 * time.Second
+
+// pipeline holds the appenders built from a Config
+type pipeline struct {
+	levels    map[string]slogger.Level
+	appenders map[string]slogger.Appender // by logger prefix
+	stripDirs map[string]int              // by logger prefix
+	closers   []func() error              // in the order to call them
+	files     map[string]*rollingFile     // by absolute path, closed after closers
+}
+
+// rollingFile is a RollingFileAppender that a pipeline, rather than
+// any appender wrapping it, closes, so that it can be handed on to the
+// pipeline that replaces it.
+type rollingFile struct {
+	config    AppenderConfig
+	formatter slogger.Formatter
+	appender  *rolling_file_appender.RollingFileAppender
+}
+
+// build creates the appenders described by config, which must be
+// valid.  If building fails, the appenders already built are closed.
+//
+// A log file open in previous, which may be nil, is reused rather than
+// opened a second time, so that two RollingFileAppenders never write
+// the same file.  Its settings other than formatter cannot change.
+func build(config *Config, errHandler func(error), previous *pipeline) (*pipeline, error) {
+	built := &pipeline{
+		levels:    make(map[string]slogger.Level),
+		appenders: make(map[string]slogger.Appender),
+		stripDirs: make(map[string]int),
+		files:     make(map[string]*rollingFile),
+	}
+
+	for prefix, levelStr := range config.Levels {
+		level, _ := slogger.NewLevel(levelStr)
+		built.levels[prefix] = level
+	}
+	_, hasRoot := built.levels["*"]
+	if _, found := built.levels[""]; !hasRoot && !found {
+		built.levels["*"] = slogger.TRACE
+	}
+
+	for i, logger := range config.Loggers {
+		appenders := make(multiAppender, len(logger.Appenders))
+		for j := range logger.Appenders {
+			path := fmt.Sprintf("loggers[%d].appenders[%d]", i, j)
+			appender, err := built.buildAppender(path, &logger.Appenders[j], errHandler, previous)
+			if err != nil {
+				built.close(previous)
+				return nil, err
+			}
+			appenders[j] = appender
+		}
+
+		built.appenders[logger.Prefix] = appenders
+		built.stripDirs[logger.Prefix] = logger.StripDirs
+	}
+
+	return built, nil
+}
+
+func (self *pipeline) buildAppender(path string, config *AppenderConfig, errHandler func(error), previous *pipeline) (slogger.Appender, error) {
+	formatter, _ := parseFormatter(config.Formatter)
+
+	switch config.Type {
+	case "stdout", "stderr":
+		if config.Type == "stderr" {
+			return slogger.NewFileAppenderWithFormatter(os.Stderr, formatter), nil
+		}
+		return slogger.NewFileAppenderWithFormatter(os.Stdout, formatter), nil
+
+	case "rolling_file":
+		file, err := self.rollingFile(config, formatter, previous)
+		if err != nil {
+			return nil, &BuildError{path, err}
+		}
+		return &rollingFileAppender{file.appender}, nil
+
+	case "network":
+		framing, _ := parseFraming(config.Framing)
+		appender, err := network_appender.NewBuilder(config.Network, config.Address, config.BufferSize, errHandler).
+			WithFraming(framing).
+			WithFormatter(formatter).
+			Build()
+		if err != nil {
+			return nil, &BuildError{path, err}
+		}
+		self.closers = append(self.closers, appender.Close)
+		return appender, nil
+
+	case "syslog":
+		format, _ := parseSyslogFormat(config.SyslogFormat)
+		facility, _ := parseFacility(config.Facility)
+		appender, err := syslog_appender.NewBuilder(config.Network, config.Address).
+			WithFormat(format).
+			WithFacility(facility).
+			WithAppName(config.AppName).
+			WithHostname(config.Hostname).
+			WithFormatter(formatter).
+			Build()
+		if err != nil {
+			return nil, &BuildError{path, err}
+		}
+		self.closers = append(self.closers, appender.Close)
+		return appender, nil
+
+	case "level_filter":
+		level, _ := slogger.NewLevel(config.Level)
+		appender, err := self.buildAppender(path+".appender", config.Appender, errHandler, previous)
+		if err != nil {
+			return nil, err
+		}
+		return slogger.LevelFilter(level, appender), nil
+
+	case "retaining":
+		level, _ := slogger.NewLevel(config.Level)
+		appender, err := self.buildAppender(path+".appender", config.Appender, errHandler, previous)
+		if err != nil {
+			return nil, err
+		}
+		return retaining_level_filter_appender.New(config.CategoryKey, config.Capacity, level, appender), nil
+
+	case "async":
+		start := len(self.closers)
+		appender, err := self.buildAppender(path+".appender", config.Appender, errHandler, previous)
+		if err != nil {
+			return nil, err
+		}
+
+		overflow, _ := parseOverflow(config.Overflow)
+		dropReportInterval, _ := parseDuration(config.DropReportInterval)
+		if dropReportInterval == 0 {
+			dropReportInterval = 10 * time.Second
+		}
+		batchLinger, _ := parseDuration(config.BatchLinger)
+		batchSize := config.BatchSize
+		if batchSize == 0 {
+			batchSize = 1
+		}
+
+		asyncAppender := async_appender.NewBuilder(appender, config.Capacity, errHandler).
+			WithOverflowPolicy(overflow, dropReportInterval).
+			WithBatching(batchSize, batchLinger).
+			WithWorkers(config.Workers, config.ShardKey).
+			Build()
+
+		// The AsyncAppender closes the appender it wraps if that is an
+		// io.Closer, and its queued logs must be appended before the
+		// appenders beneath it are closed.
+		closers := append([]func() error{}, self.closers[start:]...)
+		self.closers = append(self.closers[:start], func() error {
+			return asyncAppender.CloseWithTimeout(asyncCloseTimeout)
+		})
+		if _, ok := appender.(io.Closer); !ok {
+			self.closers = append(self.closers, closers...)
+		}
+		return asyncAppender, nil
+	}
+
+	return nil, &BuildError{path, fmt.Errorf("unknown appender type %q", config.Type)}
+}
+
+// rollingFile returns the RollingFileAppender for config's path,
+// reusing the one already open in this pipeline or in previous.
+func (self *pipeline) rollingFile(config *AppenderConfig, formatter slogger.Formatter, previous *pipeline) (*rollingFile, error) {
+	absPath, err := filepath.Abs(config.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	if file, found := self.files[absPath]; found {
+		if file.config != *config {
+			return nil, fmt.Errorf("%s is already configured with different settings", absPath)
+		}
+		return file, nil
+	}
+
+	if previous != nil {
+		if file, found := previous.files[absPath]; found {
+			if !sameFileSettings(file.config, *config) {
+				return nil, fmt.Errorf("%s is open with different settings, which cannot be changed by a reload", absPath)
+			}
+
+			// the formatter is set by the Manager once the new
+			// pipeline replaces previous
+			reused := &rollingFile{*config, formatter, file.appender}
+			self.files[absPath] = reused
+			return reused, nil
+		}
+	}
+
+	maxFileSize, _ := parseSize(config.MaxFileSize)
+	maxDuration, _ := parseDuration(config.MaxDuration)
+	builder := rolling_file_appender.NewBuilder(
+		config.Path,
+		maxFileSize,
+		maxDuration,
+		config.MaxRotatedLogs,
+		config.RotateIfExists,
+		nil,
+	).WithFormatter(formatter)
+	if config.Compress {
+		builder = builder.WithLogCompression(config.MaxUncompressedLogs)
+	}
+
+	appender, err := builder.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	file := &rollingFile{*config, formatter, appender}
+	self.files[absPath] = file
+	return file, nil
+}
+
+// sameFileSettings reports whether a and b configure a rolling_file
+// the same way, other than its formatter.
+func sameFileSettings(a, b AppenderConfig) bool {
+	a.Formatter, b.Formatter = "", ""
+	return a == b
+}
+
+// appenderFor returns the appender for Loggers with prefix, which is
+// nil if neither prefix nor "*" is configured.
+func (self *pipeline) appenderFor(prefix string) slogger.Appender {
+	if appender, found := self.appenders[prefix]; found {
+		return appender
+	}
+	return self.appenders["*"]
+}
+
+func (self *pipeline) stripDirsFor(prefix string) int {
+	if stripDirs, found := self.stripDirs[prefix]; found {
+		return stripDirs
+	}
+	return self.stripDirs["*"]
+}
+
+// close closes the pipeline's appenders, except for the log files
+// handed on to keep, which may be nil.
+func (self *pipeline) close(keep *pipeline) []error {
+	var errs []error
+	for _, closer := range self.closers {
+		if err := closer(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for absPath, file := range self.files {
+		if keep != nil && keep.files[absPath] != nil && keep.files[absPath].appender == file.appender {
+			continue
+		}
+		if err := file.appender.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// rollingFileAppender passes logs to a RollingFileAppender that is
+// closed by its pipeline.  It is not an io.Closer, so that an
+// AsyncAppender wrapping it does not close it.
+type rollingFileAppender struct {
+	appender *rolling_file_appender.RollingFileAppender
+}
+
+func (self *rollingFileAppender) Append(log *slogger.Log) error {
+	return self.appender.Append(log)
+}
+
+func (self *rollingFileAppender) AppendBatch(logs []*slogger.Log) error {
+	return self.appender.AppendBatch(logs)
+}
+
+func (self *rollingFileAppender) Flush() error {
+	return self.appender.Flush()
+}
+
+func (self *rollingFileAppender) SetFormatter(formatter slogger.Formatter) bool {
+	return self.appender.SetFormatter(formatter)
+}
+
+func (self *rollingFileAppender) Rotate() error {
+	return self.appender.Rotate()
+}
+
+func (self *rollingFileAppender) Reopen() error {
+	return self.appender.Reopen()
+}
+
+// multiAppender appends to each of its Appenders
+type multiAppender []slogger.Appender
+
+func (self multiAppender) Append(log *slogger.Log) error {
+	return self.each(func(appender slogger.Appender) error {
+		return appender.Append(log)
+	})
+}
+
+func (self multiAppender) Flush() error {
+	return self.each(func(appender slogger.Appender) error {
+		return appender.Flush()
+	})
+}
+
+func (self multiAppender) Enabled(level slogger.Level) bool {
+	for _, appender := range self {
+		if slogger.AppenderEnabled(appender, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetFormatter sets the Formatter of each Appender that supports one,
+// and reports whether they all did.
+func (self multiAppender) SetFormatter(formatter slogger.Formatter) bool {
+	set := true
+	for _, appender := range self {
+		if !slogger.SetAppenderFormatter(appender, formatter) {
+			set = false
+		}
+	}
+	return set
+}
+
+func (self multiAppender) each(f func(appender slogger.Appender) error) error {
+	var errs []error
+	for _, appender := range self {
+		if err := f(appender); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return &AppendError{errs}
+	}
+}
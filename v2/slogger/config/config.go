@@ -0,0 +1,179 @@
+// Copyright 2026 MongoDB, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package config builds slogger Loggers and their appender pipelines
+// from a JSON document, and rebuilds them when the document changes.
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Config describes the levels, and the appenders of each Logger, that
+// a Manager provides.  For example:
+//
+//	{
+//	  "levels": {"*": "info", "repl": "debug"},
+//	  "loggers": [
+//	    {
+//	      "prefix": "*",
+//	      "stripDirs": 1,
+//	      "appenders": [
+//	        {"type": "stderr", "formatter": "logfmt"},
+//	        {
+//	          "type": "async", "capacity": 1024, "overflow": "drop_below_warn",
+//	          "appender": {
+//	            "type": "rolling_file", "path": "/var/log/server.log",
+//	            "maxFileSize": "100MB", "maxDuration": "24h",
+//	            "maxRotatedLogs": 10, "compress": true
+//	          }
+//	        }
+//	      ]
+//	    }
+//	  ]
+//	}
+type Config struct {
+	// Levels are the LevelRegistry thresholds by prefix.  "*" is the
+	// level for prefixes without one of their own, and defaults to
+	// "trace" so that only appenders' levels apply.
+	Levels map[string]string `json:"levels,omitempty"`
+
+	Loggers []LoggerConfig `json:"loggers"`
+}
+
+type LoggerConfig struct {
+	// Prefix selects the Loggers this applies to.  "*" applies to
+	// Loggers whose prefix is not configured otherwise.
+	Prefix    string           `json:"prefix"`
+	StripDirs int              `json:"stripDirs,omitempty"`
+	Appenders []AppenderConfig `json:"appenders"`
+}
+
+// AppenderConfig describes an appender.  Type selects which of the
+// other fields apply:
+//
+//	stdout, stderr  formatter
+//	rolling_file    path, maxFileSize, maxDuration, maxRotatedLogs,
+//	                rotateIfExists, compress, maxUncompressedLogs,
+//	                formatter
+//	network         network, address, bufferSize, framing, formatter
+//	syslog          network, address, syslogFormat, facility,
+//	                appName, hostname, formatter
+//	level_filter    level, appender
+//	retaining       categoryKey, capacity, level, appender
+//	async           capacity, overflow, dropReportInterval,
+//	                batchSize, batchLinger, workers, shardKey,
+//	                appender
+//
+// Sizes are a number of bytes with an optional K, KB, M, MB, G or GB
+// suffix, where a KB is 1024 bytes.  Durations are as for
+// time.ParseDuration.  Formatters are "text" (the default), "text_tz",
+// "json" and "logfmt".
+type AppenderConfig struct {
+	Type      string          `json:"type"`
+	Formatter string          `json:"formatter,omitempty"`
+	Level     string          `json:"level,omitempty"`
+	Appender  *AppenderConfig `json:"appender,omitempty"`
+
+	// rolling_file
+	Path                string `json:"path,omitempty"`
+	MaxFileSize         string `json:"maxFileSize,omitempty"`
+	MaxDuration         string `json:"maxDuration,omitempty"`
+	MaxRotatedLogs      int    `json:"maxRotatedLogs,omitempty"`
+	RotateIfExists      bool   `json:"rotateIfExists,omitempty"`
+	Compress            bool   `json:"compress,omitempty"`
+	MaxUncompressedLogs int    `json:"maxUncompressedLogs,omitempty"`
+
+	// network and syslog
+	Network      string `json:"network,omitempty"`
+	Address      string `json:"address,omitempty"`
+	BufferSize   int    `json:"bufferSize,omitempty"`   // network
+	Framing      string `json:"framing,omitempty"`      // network: "newline" or "length_prefix"
+	SyslogFormat string `json:"syslogFormat,omitempty"` // syslog: "rfc5424" or "rfc3164"
+	Facility     string `json:"facility,omitempty"`     // syslog: such as "daemon" or "local3"
+	AppName      string `json:"appName,omitempty"`      // syslog
+	Hostname     string `json:"hostname,omitempty"`     // syslog
+
+	// retaining and async
+	CategoryKey string `json:"categoryKey,omitempty"` // retaining
+	Capacity    int    `json:"capacity,omitempty"`
+
+	// async
+	Overflow           string `json:"overflow,omitempty"` // "block", "drop_newest", "drop_oldest" or "drop_below_<level>"
+	DropReportInterval string `json:"dropReportInterval,omitempty"`
+	BatchSize          int    `json:"batchSize,omitempty"`
+	BatchLinger        string `json:"batchLinger,omitempty"`
+	Workers            int    `json:"workers,omitempty"`
+	ShardKey           string `json:"shardKey,omitempty"`
+}
+
+// Parse decodes and validates a JSON Config.  Unknown fields are
+// errors, so that a misspelt option is not silently ignored.
+func Parse(data []byte) (*Config, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	config := &Config{}
+	if err := decoder.Decode(config); err != nil {
+		return nil, &ParseError{err}
+	}
+
+	if errs := config.Validate(); len(errs) > 0 {
+		return nil, &InvalidConfigError{errs}
+	}
+	return config, nil
+}
+
+// Load reads and parses the Config in the file at path.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Validate returns a ValidationError for each problem with the Config.
+// It does not open files or connections, so a Config that is valid
+// can still fail to build.
+func (self *Config) Validate() []error {
+	validator := &validator{}
+
+	for prefix, levelStr := range self.Levels {
+		validator.level(fmt.Sprintf("levels[%q]", prefix), levelStr)
+	}
+
+	prefixes := make(map[string]bool)
+	for i, logger := range self.Loggers {
+		path := fmt.Sprintf("loggers[%d]", i)
+		if prefixes[logger.Prefix] {
+			validator.fail(path+".prefix", "duplicate prefix %q", logger.Prefix)
+		}
+		prefixes[logger.Prefix] = true
+
+		if logger.StripDirs < 0 {
+			validator.fail(path+".stripDirs", "must not be negative")
+		}
+
+		for j := range logger.Appenders {
+			validator.appender(fmt.Sprintf("%s.appenders[%d]", path, j), &logger.Appenders[j])
+		}
+	}
+
+	return validator.errs
+}
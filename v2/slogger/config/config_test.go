@@ -0,0 +1,421 @@
+// Copyright 2026 MongoDB, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mongodb/slogger/v2/slogger"
+	"github.com/mongodb/slogger/v2/slogger/async_appender"
+	"github.com/mongodb/slogger/v2/slogger/retaining_level_filter_appender"
+	. "github.com/mongodb/slogger/v2/slogger/test_util"
+)
+
+func TestBuildPipeline(test *testing.T) {
+	dir := tempDir(test)
+	config := parse(test, `{
+		"levels": {"*": "info", "repl": "debug"},
+		"loggers": [{
+			"prefix": "*",
+			"appenders": [{
+				"type": "async", "capacity": 16, "overflow": "drop_below_warn",
+				"appender": {
+					"type": "level_filter", "level": "debug",
+					"appender": {
+						"type": "rolling_file", "path": "`+filepath.Join(dir, "server.log")+`",
+						"maxFileSize": "1MB", "maxDuration": "24h", "maxRotatedLogs": 5,
+						"compress": true, "formatter": "logfmt"
+					}
+				}
+			}]
+		}]
+	}`)
+
+	manager, err := NewManager(config, nil)
+	if err != nil {
+		test.Fatalf("NewManager() failed: %v", err)
+	}
+
+	appender := manager.current.appenderFor("mongod").(multiAppender)
+	asyncAppender, ok := appender[0].(*async_appender.AsyncAppender)
+	if !ok {
+		test.Fatalf("Expected an AsyncAppender. Received: %T", appender[0])
+	}
+	if _, ok := asyncAppender.Appender.(*slogger.FilterAppender); !ok {
+		test.Errorf("Expected the AsyncAppender to wrap a FilterAppender. Received: %T", asyncAppender.Appender)
+	}
+
+	repl := manager.Logger("repl")
+	repl.Logf(slogger.DEBUG, "Replicated %d ops", 3)
+	manager.Logger("query").Logf(slogger.DEBUG, "Dropped by level")
+	AssertNoErrors(test, manager.Close())
+
+	contents := readFile(test, filepath.Join(dir, "server.log"))
+	if !strings.Contains(contents, `prefix=repl`) || !strings.Contains(contents, `msg="Replicated 3 ops"`) {
+		test.Errorf("Expected repl's log in logfmt. Received: %s", contents)
+	}
+	if strings.Contains(contents, "Dropped by level") {
+		test.Errorf("Expected query's debug log to be dropped. Received: %s", contents)
+	}
+}
+
+func TestValidationErrors(test *testing.T) {
+	_, err := Parse([]byte(`{
+		"levels": {"db": "loud"},
+		"loggers": [
+			{"prefix": "a", "appenders": [
+				{"type": "rolling_file", "maxFileSize": "10 parsecs", "capacity": 3},
+				{"type": "async", "capacity": 8, "overflow": "drop_everything"},
+				{"type": "retaining", "categoryKey": "cat", "capacity": 2, "level": "info",
+				 "appender": {"type": "printer"}}
+			]},
+			{"prefix": "a", "stripDirs": -1, "appenders": []}
+		]
+	}`))
+
+	invalid, ok := err.(*InvalidConfigError)
+	if !ok {
+		test.Fatalf("Expected an InvalidConfigError. Received: %v", err)
+	}
+
+	expected := []string{
+		`levels["db"]: unknown level "loud"`,
+		"loggers[0].appenders[0].capacity: is not used by type \"rolling_file\"",
+		"loggers[0].appenders[0].path: is required",
+		`loggers[0].appenders[0].maxFileSize: invalid size "10 parsecs"`,
+		`loggers[0].appenders[1].overflow: unknown overflow policy "drop_everything"`,
+		"loggers[0].appenders[1].appender: is required",
+		`loggers[0].appenders[2].appender.type: unknown appender type "printer"`,
+		`loggers[1].prefix: duplicate prefix "a"`,
+		"loggers[1].stripDirs: must not be negative",
+	}
+	if len(invalid.Errs) != len(expected) {
+		test.Fatalf("Expected %d errors. Received: %v", len(expected), invalid.Errs)
+	}
+	for i, err := range invalid.Errs {
+		if !IsValidationError(err) || err.Error() != expected[i] {
+			test.Errorf("Expected %q. Received: %q", expected[i], err)
+		}
+	}
+}
+
+func TestParseErrors(test *testing.T) {
+	_, err := Parse([]byte(`{"loggers": [{"prefix": "a", "apenders": []}]}`))
+	if !IsParseError(err) {
+		test.Errorf("Expected a ParseError for an unknown field. Received: %v", err)
+	}
+
+	_, err = Parse([]byte(`{"loggers": [`))
+	if !IsParseError(err) {
+		test.Errorf("Expected a ParseError for truncated JSON. Received: %v", err)
+	}
+}
+
+func TestParseSize(test *testing.T) {
+	expected := map[string]int64{
+		"":      0,
+		"512":   512,
+		"512B":  512,
+		"4k":    4 << 10,
+		"100MB": 100 << 20,
+		"2 GB":  2 << 30,
+	}
+	for sizeStr, size := range expected {
+		if parsed, err := parseSize(sizeStr); err != nil || parsed != size {
+			test.Errorf("Expected %q to be %d. Received: %d, %v", sizeStr, size, parsed, err)
+		}
+	}
+
+	for _, sizeStr := range []string{"-1", "MB", "1TB", "99999999999GB"} {
+		if _, err := parseSize(sizeStr); err == nil {
+			test.Errorf("Expected %q to be invalid", sizeStr)
+		}
+	}
+}
+
+func TestReload(test *testing.T) {
+	dir := tempDir(test)
+	first, second := filepath.Join(dir, "first.log"), filepath.Join(dir, "second.log")
+
+	manager, err := NewManager(parse(test, rollingFileConfig(first, "info")), nil)
+	if err != nil {
+		test.Fatalf("NewManager() failed: %v", err)
+	}
+	defer manager.Close()
+
+	logger := manager.Logger("mongod")
+	logger.Logf(slogger.INFO, "Before reload")
+	logger.Logf(slogger.DEBUG, "Debug before reload")
+
+	configPath := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(configPath, []byte(rollingFileConfig(second, "debug")), 0666); err != nil {
+		test.Fatalf("Failed to write config: %v", err)
+	}
+	if err := manager.ReloadFile(configPath); err != nil {
+		test.Fatalf("ReloadFile() failed: %v", err)
+	}
+
+	logger.Logf(slogger.DEBUG, "Debug after reload")
+	AssertNoErrors(test, logger.Flush())
+
+	firstContents := readFile(test, first)
+	if !strings.Contains(firstContents, "Before reload") || strings.Contains(firstContents, "Debug") {
+		test.Errorf("Unexpected contents of the first log: %s", firstContents)
+	}
+	if secondContents := readFile(test, second); !strings.Contains(secondContents, "Debug after reload") {
+		test.Errorf("Expected the debug log in the second log. Received: %s", secondContents)
+	}
+}
+
+func TestReloadSamePath(test *testing.T) {
+	dir := tempDir(test)
+	path := filepath.Join(dir, "server.log")
+	config := func(level, formatter string, maxRotatedLogs int) *Config {
+		return &Config{
+			Levels: map[string]string{"*": level},
+			Loggers: []LoggerConfig{{
+				Prefix: "*",
+				Appenders: []AppenderConfig{{
+					Type:     "async",
+					Capacity: 16,
+					Appender: &AppenderConfig{
+						Type:           "rolling_file",
+						Path:           path,
+						MaxRotatedLogs: maxRotatedLogs,
+						RotateIfExists: true,
+						Formatter:      formatter,
+					},
+				}},
+			}},
+		}
+	}
+
+	manager, err := NewManager(config("info", "text", 5), nil)
+	if err != nil {
+		test.Fatalf("NewManager() failed: %v", err)
+	}
+	defer manager.Close()
+
+	logger := manager.Logger("mongod")
+	logger.Logf(slogger.INFO, "Before reload")
+
+	if err := manager.Reload(config("debug", "json", 5)); err != nil {
+		test.Fatalf("Reload() failed: %v", err)
+	}
+	logger.Logf(slogger.DEBUG, "After reload")
+
+	if err := manager.Reload(config("debug", "json", 6)); !IsBuildError(err) {
+		test.Errorf("Expected a BuildError changing the file's settings. Received: %v", err)
+	}
+	logger.Logf(slogger.DEBUG, "After failed reload")
+	AssertNoErrors(test, logger.Flush())
+
+	// the file was neither rotated nor reopened by the reload
+	rotated, err := filepath.Glob(path + ".*")
+	if err != nil || len(rotated) != 0 {
+		test.Errorf("Expected no rotated log files. Received: %v %v", rotated, err)
+	}
+
+	contents := readFile(test, path)
+	if !strings.Contains(contents, "Before reload") ||
+		!strings.Contains(contents, `"message":"After reload"`) ||
+		!strings.Contains(contents, `"message":"After failed reload"`) {
+		test.Errorf("Expected every log in the one file. Received: %s", contents)
+	}
+}
+
+func TestReloadWithBlockedAppender(test *testing.T) {
+	// stdout is a pipe that is not read until the end of the test, so
+	// the AsyncAppender's worker and then its producers block
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		test.Fatalf("Failed to create pipe: %v", err)
+	}
+	defer reader.Close()
+	defer writer.Close()
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+
+	manager, err := NewManager(parse(test, `{
+		"loggers": [{"prefix": "*", "appenders": [
+			{"type": "async", "capacity": 1, "overflow": "block", "appender": {"type": "stdout"}}
+		]}]
+	}`), nil)
+	os.Stdout = stdout
+	if err != nil {
+		test.Fatalf("NewManager() failed: %v", err)
+	}
+	defer manager.Close()
+
+	logger := manager.Logger("mongod")
+	slot := logger.Appenders[0].(*swapAppender)
+	message := strings.Repeat("x", 1<<17)
+	logged := make(chan struct{})
+	go func() {
+		defer close(logged)
+		for i := 0; i < 3; i++ {
+			logger.Logf(slogger.INFO, "%s", message)
+		}
+	}()
+
+	reloaded := make(chan error, 1)
+	path := filepath.Join(tempDir(test), "server.log")
+	go func() {
+		reloaded <- manager.Reload(parse(test, rollingFileConfig(path, "info")))
+	}()
+
+	withinTimeout(test, "swap the appenders", func() {
+		for {
+			if _, ok := slot.get().(multiAppender)[0].(*rollingFileAppender); ok {
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	})
+	withinTimeout(test, "create a Logger", func() {
+		manager.Logger("other")
+	})
+	withinTimeout(test, "log through the new appenders", func() {
+		logger.Logf(slogger.INFO, "After reload")
+	})
+
+	go io.Copy(io.Discard, reader)
+	withinTimeout(test, "finish the reload", func() {
+		if err := <-reloaded; err != nil {
+			test.Errorf("Reload() failed: %v", err)
+		}
+	})
+	withinTimeout(test, "release the blocked Appends", func() {
+		<-logged
+	})
+}
+
+func TestFailedReloadKeepsPipeline(test *testing.T) {
+	dir := tempDir(test)
+	path := filepath.Join(dir, "server.log")
+
+	manager, err := NewManager(parse(test, rollingFileConfig(path, "info")), nil)
+	if err != nil {
+		test.Fatalf("NewManager() failed: %v", err)
+	}
+	defer manager.Close()
+
+	err = manager.Reload(&Config{Loggers: []LoggerConfig{{
+		Prefix:    "*",
+		Appenders: []AppenderConfig{{Type: "level_filter", Level: "info"}},
+	}}})
+	if !IsInvalidConfigError(err) {
+		test.Errorf("Expected an InvalidConfigError. Received: %v", err)
+	}
+
+	missingDir := filepath.Join(dir, "missing", "server.log")
+	if err := manager.Reload(parse(test, rollingFileConfig(missingDir, "debug"))); !IsBuildError(err) {
+		test.Errorf("Expected a BuildError. Received: %v", err)
+	}
+
+	logger := manager.Logger("mongod")
+	logger.Logf(slogger.INFO, "Still logging")
+	logger.Logf(slogger.DEBUG, "Debug still dropped")
+	AssertNoErrors(test, logger.Flush())
+
+	contents := readFile(test, path)
+	if !strings.Contains(contents, "Still logging") || strings.Contains(contents, "Debug still dropped") {
+		test.Errorf("Expected the original pipeline and levels to be kept. Received: %s", contents)
+	}
+}
+
+func TestUnconfiguredPrefix(test *testing.T) {
+	manager, err := NewManager(parse(test, `{
+		"loggers": [{"prefix": "mongod", "stripDirs": 2, "appenders": [
+			{"type": "retaining", "categoryKey": "cat", "capacity": 2, "level": "info",
+			 "appender": {"type": "stderr"}}
+		]}]
+	}`), nil)
+	if err != nil {
+		test.Fatalf("NewManager() failed: %v", err)
+	}
+	defer manager.Close()
+
+	if manager.Logger("mongod").StripDirs != 2 {
+		test.Errorf("Expected mongod's StripDirs to be configured")
+	}
+	appender := manager.current.appenderFor("mongod").(multiAppender)
+	if _, ok := appender[0].(*retaining_level_filter_appender.RetainingLevelFilterAppender); !ok {
+		test.Errorf("Expected a RetainingLevelFilterAppender. Received: %T", appender[0])
+	}
+
+	other := manager.Logger("other")
+	if other.Enabled(slogger.FATAL) {
+		test.Errorf("Expected a Logger with no configured appenders to be disabled")
+	}
+	if manager.Logger("other") != other {
+		test.Errorf("Expected the same Logger for the same prefix")
+	}
+}
+
+func rollingFileConfig(path, rootLevel string) string {
+	return `{
+		"levels": {"*": "` + rootLevel + `"},
+		"loggers": [{"prefix": "*", "appenders": [{"type": "rolling_file", "path": "` + path + `"}]}]
+	}`
+}
+
+func parse(test *testing.T, data string) *Config {
+	test.Helper()
+	config, err := Parse([]byte(data))
+	if err != nil {
+		test.Fatalf("Parse() failed: %v", err)
+	}
+	return config
+}
+
+func tempDir(test *testing.T) string {
+	dir, err := os.MkdirTemp("", "config")
+	if err != nil {
+		test.Fatalf("Failed to create temp dir: %v", err)
+	}
+	test.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+func withinTimeout(test *testing.T, action string, f func()) {
+	test.Helper()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f()
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		test.Fatalf("Timed out waiting to %s", action)
+	}
+}
+
+func readFile(test *testing.T, path string) string {
+	test.Helper()
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		test.Fatalf("Failed to read %s: %v", path, err)
+	}
+	return string(contents)
+}
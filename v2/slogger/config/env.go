@@ -0,0 +1,174 @@
+// Copyright 2026 MongoDB, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mongodb/slogger/v2/slogger"
+)
+
+const (
+	// EnvLevel holds comma separated levels.  A level on its own sets
+	// the level for every prefix, and prefix=level sets it for a
+	// prefix, as in "info,repl=debug".
+	EnvLevel = "SLOGGER_LEVEL"
+
+	// EnvFormat names the formatter for a Logger's appenders: "text",
+	// "text_tz", "json" or "logfmt".
+	EnvFormat = "SLOGGER_FORMAT"
+
+	// EnvStripDirs sets a Logger's StripDirs.
+	EnvStripDirs = "SLOGGER_STRIP_DIRS"
+)
+
+// envPrefix is the prefix of every variable that ParseEnv reads.
+// Other variables with this prefix are reported as unknown.
+const envPrefix = "SLOGGER_"
+
+// Env holds the overrides read from environment variables.
+type Env struct {
+	Levels map[string]slogger.Level // by prefix, with "*" for every prefix
+
+	Formatter slogger.Formatter // nil if not set
+
+	StripDirs    int
+	HasStripDirs bool
+}
+
+// ParseEnv reads the SLOGGER_ variables in environ, which is formatted
+// as by os.Environ.  It returns an EnvError for each variable that is
+// unknown or invalid, and the Env holding the others.
+func ParseEnv(environ []string) (*Env, []error) {
+	env := &Env{Levels: make(map[string]slogger.Level)}
+	var errs []error
+
+	variables := make(map[string]string)
+	for _, variable := range environ {
+		if name, value, found := strings.Cut(variable, "="); found && strings.HasPrefix(name, envPrefix) {
+			variables[name] = value
+		}
+	}
+
+	names := make([]string, 0, len(variables))
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := variables[name]
+
+		switch name {
+		case EnvLevel:
+			errs = append(errs, env.parseLevels(value)...)
+		case EnvFormat:
+			formatter, err := parseFormatter(value)
+			if err != nil || formatter == nil {
+				errs = append(errs, EnvError{name, fmt.Sprintf("unknown formatter %q", value)})
+				continue
+			}
+			env.Formatter = formatter
+		case EnvStripDirs:
+			stripDirs, err := strconv.Atoi(value)
+			if err != nil || stripDirs < 0 {
+				errs = append(errs, EnvError{name, fmt.Sprintf("invalid number of directories %q", value)})
+				continue
+			}
+			env.StripDirs = stripDirs
+			env.HasStripDirs = true
+		default:
+			errs = append(errs, EnvError{name, "unknown variable"})
+		}
+	}
+
+	return env, errs
+}
+
+func (self *Env) parseLevels(value string) []error {
+	var errs []error
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		prefix, levelStr, found := strings.Cut(item, "=")
+		if !found {
+			prefix, levelStr = "*", item
+		}
+
+		prefix = strings.TrimSpace(prefix)
+		if prefix == "" {
+			errs = append(errs, EnvError{EnvLevel, fmt.Sprintf("missing prefix in %q", item)})
+			continue
+		}
+
+		level, err := slogger.NewLevel(strings.TrimSpace(levelStr))
+		if err != nil {
+			errs = append(errs, EnvError{EnvLevel, fmt.Sprintf("unknown level %q for %s", levelStr, prefix)})
+			continue
+		}
+		self.Levels[prefix] = level
+	}
+	return errs
+}
+
+// Apply sets logger's levels, StripDirs and the Formatter of its
+// appenders.  Levels are set on logger.Levels, which is given a new
+// LevelRegistry if it is nil.  An appender that is not a
+// slogger.FormatterSetter, or that wraps one that is not, is reported
+// with an EnvError.
+//
+// Apply should be called before logger is used, as logger's fields
+// are not guarded against concurrent logging.
+func (self *Env) Apply(logger *slogger.Logger) []error {
+	var errs []error
+
+	if len(self.Levels) > 0 {
+		if logger.Levels == nil {
+			logger.Levels = slogger.NewLevelRegistry(slogger.TRACE)
+		}
+		for prefix, level := range self.Levels {
+			logger.Levels.SetLevel(prefix, level)
+		}
+	}
+
+	if self.HasStripDirs {
+		logger.StripDirs = self.StripDirs
+	}
+
+	if self.Formatter != nil {
+		for _, appender := range logger.Appenders {
+			if !slogger.SetAppenderFormatter(appender, self.Formatter) {
+				errs = append(errs, EnvError{EnvFormat, fmt.Sprintf("%T, or an appender it wraps, does not support formatters", appender)})
+			}
+		}
+	}
+
+	return errs
+}
+
+// ApplyEnv applies the SLOGGER_ environment variables to logger, as
+// ParseEnv and Apply do.  Valid variables are applied even when others
+// are reported.
+func ApplyEnv(logger *slogger.Logger) []error {
+	env, errs := ParseEnv(os.Environ())
+	return append(errs, env.Apply(logger)...)
+}
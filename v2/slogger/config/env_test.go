@@ -0,0 +1,140 @@
+// Copyright 2026 MongoDB, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mongodb/slogger/v2/slogger"
+	"github.com/mongodb/slogger/v2/slogger/async_appender"
+)
+
+func TestApplyEnv(test *testing.T) {
+	env, errs := ParseEnv([]string{
+		"HOME=/root",
+		"SLOGGER_LEVEL=warn, repl=debug,storage.*=error",
+		"SLOGGER_FORMAT=json",
+		"SLOGGER_STRIP_DIRS=2",
+	})
+	if len(errs) != 0 {
+		test.Fatalf("ParseEnv() failed: %v", errs)
+	}
+
+	buffer := new(bytes.Buffer)
+	logger := &slogger.Logger{
+		Prefix:    "repl",
+		Appenders: []slogger.Appender{slogger.LevelFilter(slogger.TRACE, slogger.NewStringAppenderWithFormatter(buffer, nil))},
+	}
+	if errs := env.Apply(logger); len(errs) != 0 {
+		test.Fatalf("Apply() failed: %v", errs)
+	}
+
+	if logger.StripDirs != 2 {
+		test.Errorf("Expected StripDirs to be 2. Received: %d", logger.StripDirs)
+	}
+
+	expected := map[string]slogger.Level{"": slogger.WARN, "repl": slogger.DEBUG, "storage": slogger.ERROR}
+	levels := logger.Levels.Levels()
+	if len(levels) != len(expected) {
+		test.Errorf("Expected levels %v. Received: %v", expected, levels)
+	}
+	for prefix, level := range expected {
+		if levels[prefix] != level {
+			test.Errorf("Expected %q to be at %v. Received: %v", prefix, level, levels[prefix])
+		}
+	}
+
+	logger.Logf(slogger.DEBUG, "Replicated")
+	logger.Logf(slogger.TRACE, "Dropped")
+	if !strings.HasPrefix(buffer.String(), "{") || !strings.Contains(buffer.String(), `"message":"Replicated"`) {
+		test.Errorf("Expected the debug log in JSON. Received: %s", buffer.String())
+	}
+	if strings.Contains(buffer.String(), "Dropped") {
+		test.Errorf("Expected the trace log to be dropped. Received: %s", buffer.String())
+	}
+}
+
+func TestEnvErrors(test *testing.T) {
+	env, errs := ParseEnv([]string{
+		"SLOGGER_LEVEL=info,repl=chatty,=debug",
+		"SLOGGER_FORMAT=xml",
+		"SLOGGER_LEVLE=debug",
+		"SLOGGER_STRIP_DIRS=-1",
+	})
+
+	expected := []string{
+		`config: SLOGGER_FORMAT: unknown formatter "xml"`,
+		`config: SLOGGER_LEVEL: unknown level "chatty" for repl`,
+		`config: SLOGGER_LEVEL: missing prefix in "=debug"`,
+		"config: SLOGGER_LEVLE: unknown variable",
+		`config: SLOGGER_STRIP_DIRS: invalid number of directories "-1"`,
+	}
+	if len(errs) != len(expected) {
+		test.Fatalf("Expected %d errors. Received: %v", len(expected), errs)
+	}
+	for i, err := range errs {
+		if !IsEnvError(err) || err.Error() != expected[i] {
+			test.Errorf("Expected %q. Received: %q", expected[i], err)
+		}
+	}
+
+	if len(env.Levels) != 1 || env.Levels["*"] != slogger.INFO {
+		test.Errorf("Expected the valid level to be kept. Received: %v", env.Levels)
+	}
+	if env.Formatter != nil || env.HasStripDirs {
+		test.Errorf("Expected no formatter or StripDirs. Received: %+v", env)
+	}
+}
+
+func TestApplyFormatterThroughWrappers(test *testing.T) {
+	buffer := new(bytes.Buffer)
+	asyncAppender := async_appender.New(slogger.NewStringAppenderWithFormatter(buffer, nil), 10, nil)
+	defer asyncAppender.CloseWithTimeout(0)
+
+	logger := &slogger.Logger{
+		Appenders: []slogger.Appender{
+			asyncAppender,
+			&countingAppender{},
+			slogger.LevelFilter(slogger.INFO, &countingAppender{}),
+		},
+	}
+
+	env := &Env{Formatter: slogger.FormatterFunc(slogger.FormatLogfmt)}
+	errs := env.Apply(logger)
+	if len(errs) != 2 || !IsEnvError(errs[0]) || !IsEnvError(errs[1]) {
+		test.Errorf("Expected an EnvError for each appender without a formatter, including wrapped ones. Received: %v", errs)
+	}
+
+	logger.Logf(slogger.WARN, "Through the AsyncAppender")
+	asyncAppender.Flush()
+	if !strings.Contains(buffer.String(), `msg="Through the AsyncAppender"`) {
+		test.Errorf("Expected the log in logfmt. Received: %s", buffer.String())
+	}
+}
+
+type countingAppender struct {
+	count int
+}
+
+func (self *countingAppender) Append(log *slogger.Log) error {
+	self.count++
+	return nil
+}
+
+func (self *countingAppender) Flush() error {
+	return nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2026 MongoDB, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+type ParseError struct {
+	Err error
+}
+
+func (self *ParseError) Error() string {
+	return fmt.Sprintf("config: Failed to parse: %s", self.Err.Error())
+}
+
+func IsParseError(err error) bool {
+	_, ok := err.(*ParseError)
+	return ok
+}
+
+// ValidationError describes a problem with the field at Path, such as
+// "loggers[0].appenders[1].appender.maxFileSize".
+type ValidationError struct {
+	Path string
+	Msg  string
+}
+
+func (self ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", self.Path, self.Msg)
+}
+
+func IsValidationError(err error) bool {
+	_, ok := err.(ValidationError)
+	return ok
+}
+
+type InvalidConfigError struct {
+	Errs []error
+}
+
+func (self *InvalidConfigError) Error() string {
+	msgs := make([]string, len(self.Errs))
+	for i, err := range self.Errs {
+		msgs[i] = err.Error()
+	}
+	return "config: Invalid config: " + strings.Join(msgs, "; ")
+}
+
+func IsInvalidConfigError(err error) bool {
+	_, ok := err.(*InvalidConfigError)
+	return ok
+}
+
+// BuildError is returned when an appender that was validated cannot
+// be created, such as a log file that cannot be opened.
+type BuildError struct {
+	Path string
+	Err  error
+}
+
+func (self *BuildError) Error() string {
+	return fmt.Sprintf("config: Failed to build %s: %s", self.Path, self.Err.Error())
+}
+
+func IsBuildError(err error) bool {
+	_, ok := err.(*BuildError)
+	return ok
+}
+
+// AppendError holds the errors returned by more than one of a
+// Logger's configured appenders.
+type AppendError struct {
+	Errs []error
+}
+
+func (self *AppendError) Error() string {
+	msgs := make([]string, len(self.Errs))
+	for i, err := range self.Errs {
+		msgs[i] = err.Error()
+	}
+	return "config: " + strings.Join(msgs, "; ")
+}
+
+func IsAppendError(err error) bool {
+	_, ok := err.(*AppendError)
+	return ok
+}
+
+type ClosedError struct{}
+
+func (ClosedError) Error() string {
+	return "config: Manager is closed"
+}
+
+func IsClosedError(err error) bool {
+	_, ok := err.(ClosedError)
+	return ok
+}
+
+// EnvError describes an environment variable that is unknown or
+// cannot be applied.
+type EnvError struct {
+	Var string
+	Msg string
+}
+
+func (self EnvError) Error() string {
+	return fmt.Sprintf("config: %s: %s", self.Var, self.Msg)
+}
+
+func IsEnvError(err error) bool {
+	_, ok := err.(EnvError)
+	return ok
+}
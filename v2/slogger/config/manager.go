@@ -0,0 +1,250 @@
+// Copyright 2026 MongoDB, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/mongodb/slogger/v2/slogger"
+)
+
+// A Manager provides Loggers whose levels and appenders come from a
+// Config, and replaces them when the Config is reloaded.
+//
+// Each Logger has a single Appender that the Manager swaps when
+// reloading, so Loggers handed out before a reload log through the
+// new appenders afterwards.  Swapping does not wait for Appends in
+// progress, so one that is still using the old appenders when they
+// are closed may return an error, such as an AsyncAppender's
+// ClosedError.
+type Manager struct {
+	levels     *slogger.LevelRegistry
+	errHandler func(error)
+
+	// reloadLock serializes Reload and Close, so that a reload
+	// reuses log files from the pipeline it replaces
+	reloadLock sync.Mutex
+
+	lock sync.Mutex
+
+	// These fields are protected by lock
+	current *pipeline
+	loggers map[string]*slogger.Logger
+	slots   map[string]*swapAppender
+	closed  bool
+}
+
+// NewManager builds the appenders described by config.  errHandler,
+// if not nil, is called with errors from appenders that log in the
+// background, such as AsyncAppenders, and with errors closing
+// appenders replaced by a reload.
+func NewManager(config *Config, errHandler func(error)) (*Manager, error) {
+	if errs := config.Validate(); len(errs) > 0 {
+		return nil, &InvalidConfigError{errs}
+	}
+
+	built, err := build(config, errHandler, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	levels := slogger.NewLevelRegistry(slogger.TRACE)
+	levels.ReplaceLevels(built.levels)
+
+	return &Manager{
+		levels:     levels,
+		errHandler: errHandler,
+		current:    built,
+		loggers:    make(map[string]*slogger.Logger),
+		slots:      make(map[string]*swapAppender),
+	}, nil
+}
+
+// Levels returns the LevelRegistry shared by the Manager's Loggers.
+// Levels set on it directly are replaced by the next reload.
+func (self *Manager) Levels() *slogger.LevelRegistry {
+	return self.levels
+}
+
+// Logger returns the Logger for prefix, creating it on first use.  A
+// prefix that is not configured uses the appenders configured for
+// "*", or none if that is not configured either.
+//
+// The Logger's StripDirs is set when it is created.  Later reloads
+// change its levels and appenders only.
+func (self *Manager) Logger(prefix string) *slogger.Logger {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	if logger, found := self.loggers[prefix]; found {
+		return logger
+	}
+
+	slot := &swapAppender{}
+	if !self.closed {
+		slot.set(self.current.appenderFor(prefix))
+	}
+
+	logger := &slogger.Logger{
+		Prefix:    prefix,
+		Appenders: []slogger.Appender{slot},
+		StripDirs: self.current.stripDirsFor(prefix),
+		Levels:    self.levels,
+	}
+	self.loggers[prefix] = logger
+	self.slots[prefix] = slot
+	return logger
+}
+
+// Reload builds the appenders described by config and swaps every
+// Logger over to them, then closes the appenders they replace.  An
+// old appender that is blocked, such as a full AsyncAppender with the
+// Block policy, does not delay the swap, and closing it releases the
+// Appends blocked on it.  If
+// config is invalid or its appenders cannot be built, the current
+// appenders are kept and an error is returned.
+//
+// A rolling_file whose path is already open keeps its
+// RollingFileAppender, so that two appenders never write the same
+// file.  Only its formatter can change; reloading it with other
+// settings changed is a BuildError.
+func (self *Manager) Reload(config *Config) error {
+	self.reloadLock.Lock()
+	defer self.reloadLock.Unlock()
+
+	if errs := config.Validate(); len(errs) > 0 {
+		return &InvalidConfigError{errs}
+	}
+
+	built, err := build(config, self.errHandler, self.currentPipeline())
+	if err != nil {
+		return err
+	}
+
+	self.lock.Lock()
+	if self.closed {
+		self.lock.Unlock()
+		built.close(nil)
+		return ClosedError{}
+	}
+
+	old := self.current
+	self.current = built
+	self.levels.ReplaceLevels(built.levels)
+	for prefix, slot := range self.slots {
+		slot.set(built.appenderFor(prefix))
+	}
+	for _, file := range built.files {
+		file.appender.SetFormatter(file.formatter)
+	}
+	self.lock.Unlock()
+
+	for _, err := range old.close(built) {
+		self.reportError(err)
+	}
+	return nil
+}
+
+// ReloadFile loads the Config in the file at path and reloads it.
+func (self *Manager) ReloadFile(path string) error {
+	config, err := Load(path)
+	if err != nil {
+		return err
+	}
+	return self.Reload(config)
+}
+
+// Close closes the current appenders.  The Manager's Loggers discard
+// logs afterwards.
+func (self *Manager) Close() []error {
+	self.reloadLock.Lock()
+	defer self.reloadLock.Unlock()
+
+	self.lock.Lock()
+	if self.closed {
+		self.lock.Unlock()
+		return nil
+	}
+
+	self.closed = true
+	for _, slot := range self.slots {
+		slot.set(nil)
+	}
+	self.lock.Unlock()
+
+	return self.current.close(nil)
+}
+
+func (self *Manager) currentPipeline() *pipeline {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	if self.closed {
+		return nil
+	}
+	return self.current
+}
+
+func (self *Manager) reportError(err error) {
+	if self.errHandler != nil {
+		self.errHandler(err)
+	}
+}
+
+// swapAppender passes logs to an Appender that can be replaced
+// without waiting for Appends in progress
+type swapAppender struct {
+	appender atomic.Value // swapped
+}
+
+type swapped struct {
+	appender slogger.Appender // nil discards logs
+}
+
+func (self *swapAppender) Append(log *slogger.Log) error {
+	appender := self.get()
+	if appender == nil {
+		return nil
+	}
+	return appender.Append(log)
+}
+
+func (self *swapAppender) Flush() error {
+	appender := self.get()
+	if appender == nil {
+		return nil
+	}
+	return appender.Flush()
+}
+
+func (self *swapAppender) Enabled(level slogger.Level) bool {
+	appender := self.get()
+	return appender != nil && slogger.AppenderEnabled(appender, level)
+}
+
+func (self *swapAppender) SetFormatter(formatter slogger.Formatter) bool {
+	appender := self.get()
+	return appender == nil || slogger.SetAppenderFormatter(appender, formatter)
+}
+
+func (self *swapAppender) get() slogger.Appender {
+	current, _ := self.appender.Load().(swapped)
+	return current.appender
+}
+
+func (self *swapAppender) set(appender slogger.Appender) {
+	self.appender.Store(swapped{appender})
+}
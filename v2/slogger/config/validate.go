@@ -0,0 +1,306 @@
+// Copyright 2026 MongoDB, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mongodb/slogger/v2/slogger"
+	"github.com/mongodb/slogger/v2/slogger/async_appender"
+	"github.com/mongodb/slogger/v2/slogger/network_appender"
+	"github.com/mongodb/slogger/v2/slogger/syslog_appender"
+)
+
+// appenderFields lists the fields, by JSON name, that each appender
+// type uses besides "type"
+var appenderFields = map[string][]string{
+	"stdout":       {"formatter"},
+	"stderr":       {"formatter"},
+	"rolling_file": {"path", "maxFileSize", "maxDuration", "maxRotatedLogs", "rotateIfExists", "compress", "maxUncompressedLogs", "formatter"},
+	"network":      {"network", "address", "bufferSize", "framing", "formatter"},
+	"syslog":       {"network", "address", "syslogFormat", "facility", "appName", "hostname", "formatter"},
+	"level_filter": {"level", "appender"},
+	"retaining":    {"categoryKey", "capacity", "level", "appender"},
+	"async":        {"capacity", "overflow", "dropReportInterval", "batchSize", "batchLinger", "workers", "shardKey", "appender"},
+}
+
+type validator struct {
+	errs []error
+}
+
+func (self *validator) fail(path string, format string, args ...interface{}) {
+	self.errs = append(self.errs, ValidationError{path, fmt.Sprintf(format, args...)})
+}
+
+func (self *validator) check(path string, err error) {
+	if err != nil {
+		self.fail(path, "%v", err)
+	}
+}
+
+func (self *validator) level(path string, levelStr string) {
+	if _, err := slogger.NewLevel(levelStr); err != nil {
+		self.fail(path, "unknown level %q", levelStr)
+	}
+}
+
+func (self *validator) required(path string, value interface{}) bool {
+	if reflect.ValueOf(value).IsZero() {
+		self.fail(path, "is required")
+		return false
+	}
+	return true
+}
+
+func (self *validator) notNegative(path string, value int) {
+	if value < 0 {
+		self.fail(path, "must not be negative")
+	}
+}
+
+func (self *validator) appender(path string, config *AppenderConfig) {
+	fields, found := appenderFields[config.Type]
+	if !found {
+		self.fail(path+".type", "unknown appender type %q", config.Type)
+		return
+	}
+	self.unusedFields(path, config, fields)
+
+	if config.Formatter != "" {
+		_, err := parseFormatter(config.Formatter)
+		self.check(path+".formatter", err)
+	}
+
+	switch config.Type {
+	case "rolling_file":
+		self.required(path+".path", config.Path)
+		_, err := parseSize(config.MaxFileSize)
+		self.check(path+".maxFileSize", err)
+		_, err = parseDuration(config.MaxDuration)
+		self.check(path+".maxDuration", err)
+		self.notNegative(path+".maxUncompressedLogs", config.MaxUncompressedLogs)
+		if config.MaxUncompressedLogs != 0 && !config.Compress {
+			self.fail(path+".maxUncompressedLogs", "requires compress")
+		}
+	case "network":
+		self.required(path+".network", config.Network)
+		self.required(path+".address", config.Address)
+		if self.required(path+".bufferSize", config.BufferSize) && config.BufferSize < 0 {
+			self.fail(path+".bufferSize", "must be positive")
+		}
+		_, err := parseFraming(config.Framing)
+		self.check(path+".framing", err)
+	case "syslog":
+		if config.Network != "" {
+			self.required(path+".address", config.Address)
+		}
+		_, err := parseSyslogFormat(config.SyslogFormat)
+		self.check(path+".syslogFormat", err)
+		_, err = parseFacility(config.Facility)
+		self.check(path+".facility", err)
+	case "level_filter":
+		if self.required(path+".level", config.Level) {
+			self.level(path+".level", config.Level)
+		}
+	case "retaining":
+		self.required(path+".categoryKey", config.CategoryKey)
+		if self.required(path+".capacity", config.Capacity) && config.Capacity < 0 {
+			self.fail(path+".capacity", "must be positive")
+		}
+		if self.required(path+".level", config.Level) {
+			self.level(path+".level", config.Level)
+		}
+	case "async":
+		if self.required(path+".capacity", config.Capacity) && config.Capacity < 0 {
+			self.fail(path+".capacity", "must be positive")
+		}
+		_, err := parseOverflow(config.Overflow)
+		self.check(path+".overflow", err)
+		_, err = parseDuration(config.DropReportInterval)
+		self.check(path+".dropReportInterval", err)
+		self.notNegative(path+".batchSize", config.BatchSize)
+		_, err = parseDuration(config.BatchLinger)
+		self.check(path+".batchLinger", err)
+		self.notNegative(path+".workers", config.Workers)
+	}
+
+	switch config.Type {
+	case "level_filter", "retaining", "async":
+		if self.required(path+".appender", config.Appender) {
+			self.appender(path+".appender", config.Appender)
+		}
+	}
+}
+
+// unusedFields fails each field of config that is set but not used by
+// its type.
+func (self *validator) unusedFields(path string, config *AppenderConfig, fields []string) {
+	used := map[string]bool{"type": true}
+	for _, field := range fields {
+		used[field] = true
+	}
+
+	value := reflect.ValueOf(config).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		name := strings.Split(value.Type().Field(i).Tag.Get("json"), ",")[0]
+		if !used[name] && !value.Field(i).IsZero() {
+			self.fail(path+"."+name, "is not used by type %q", config.Type)
+		}
+	}
+}
+
+var sizeSuffixes = []struct {
+	suffix     string
+	multiplier int64
+}{
+	{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30},
+	{"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30},
+	{"B", 1},
+}
+
+// parseSize parses a number of bytes with an optional suffix.  The
+// empty string is 0.
+func parseSize(sizeStr string) (int64, error) {
+	if sizeStr == "" {
+		return 0, nil
+	}
+
+	number, multiplier := strings.TrimSpace(sizeStr), int64(1)
+	for _, suffix := range sizeSuffixes {
+		if strings.HasSuffix(strings.ToUpper(number), suffix.suffix) {
+			number = strings.TrimSpace(number[:len(number)-len(suffix.suffix)])
+			multiplier = suffix.multiplier
+			break
+		}
+	}
+
+	size, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || size < 0 || size > (1<<63-1)/multiplier {
+		return 0, fmt.Errorf("invalid size %q", sizeStr)
+	}
+	return size * multiplier, nil
+}
+
+// parseDuration parses a non-negative duration.  The empty string is
+// 0.
+func parseDuration(durationStr string) (time.Duration, error) {
+	if durationStr == "" {
+		return 0, nil
+	}
+
+	duration, err := time.ParseDuration(durationStr)
+	if err != nil || duration < 0 {
+		return 0, fmt.Errorf("invalid duration %q", durationStr)
+	}
+	return duration, nil
+}
+
+// parseFormatter returns the named Formatter, or nil for the empty
+// name.
+func parseFormatter(name string) (slogger.Formatter, error) {
+	switch name {
+	case "":
+		return nil, nil
+	case "text":
+		return slogger.FormatterFunc(slogger.FormatLog), nil
+	case "text_tz":
+		return slogger.FormatterFunc(slogger.FormatLogWithTimezone), nil
+	case "json":
+		return slogger.FormatterFunc(slogger.FormatLogJSON), nil
+	case "logfmt":
+		return slogger.FormatterFunc(slogger.FormatLogfmt), nil
+	default:
+		return nil, fmt.Errorf("unknown formatter %q", name)
+	}
+}
+
+func parseOverflow(overflowStr string) (async_appender.OverflowPolicy, error) {
+	switch overflowStr {
+	case "", "block":
+		return async_appender.Block, nil
+	case "drop_newest":
+		return async_appender.DropNewest, nil
+	case "drop_oldest":
+		return async_appender.DropOldest, nil
+	}
+
+	if levelStr := strings.TrimPrefix(overflowStr, "drop_below_"); levelStr != overflowStr {
+		if level, err := slogger.NewLevel(levelStr); err == nil {
+			return async_appender.DropBelowLevel(level), nil
+		}
+	}
+	return async_appender.Block, fmt.Errorf("unknown overflow policy %q", overflowStr)
+}
+
+func parseFraming(framingStr string) (network_appender.Framing, error) {
+	switch framingStr {
+	case "", "newline":
+		return network_appender.NewlineFraming, nil
+	case "length_prefix":
+		return network_appender.LengthPrefixFraming, nil
+	default:
+		return network_appender.NewlineFraming, fmt.Errorf("unknown framing %q", framingStr)
+	}
+}
+
+func parseSyslogFormat(formatStr string) (syslog_appender.Format, error) {
+	switch strings.ToLower(formatStr) {
+	case "", "rfc5424":
+		return syslog_appender.RFC5424, nil
+	case "rfc3164":
+		return syslog_appender.RFC3164, nil
+	default:
+		return syslog_appender.RFC5424, fmt.Errorf("unknown syslog format %q", formatStr)
+	}
+}
+
+var facilities = map[string]syslog_appender.Facility{
+	"kern":     syslog_appender.KERN,
+	"user":     syslog_appender.USER,
+	"mail":     syslog_appender.MAIL,
+	"daemon":   syslog_appender.DAEMON,
+	"auth":     syslog_appender.AUTH,
+	"syslog":   syslog_appender.SYSLOG,
+	"lpr":      syslog_appender.LPR,
+	"news":     syslog_appender.NEWS,
+	"uucp":     syslog_appender.UUCP,
+	"cron":     syslog_appender.CRON,
+	"authpriv": syslog_appender.AUTHPRIV,
+	"ftp":      syslog_appender.FTP,
+	"local0":   syslog_appender.LOCAL0,
+	"local1":   syslog_appender.LOCAL1,
+	"local2":   syslog_appender.LOCAL2,
+	"local3":   syslog_appender.LOCAL3,
+	"local4":   syslog_appender.LOCAL4,
+	"local5":   syslog_appender.LOCAL5,
+	"local6":   syslog_appender.LOCAL6,
+	"local7":   syslog_appender.LOCAL7,
+}
+
+func parseFacility(facilityStr string) (syslog_appender.Facility, error) {
+	if facilityStr == "" {
+		return syslog_appender.USER, nil
+	}
+
+	facility, found := facilities[strings.ToLower(facilityStr)]
+	if !found {
+		return syslog_appender.USER, fmt.Errorf("unknown facility %q", facilityStr)
+	}
+	return facility, nil
+}
@@ -14,10 +14,17 @@
 
 package slogger
 
-import "sync"
+import (
+	"context"
+	"sync"
+	"time"
+)
 
+// A Context holds fields attached to a Log.  Fields are kept in the
+// order in which they were first added.
 type Context struct {
 	fields map[string]interface{}
+	keys   []string // insertion order of fields' keys
 	lock   sync.RWMutex
 }
 
@@ -27,9 +34,21 @@ func NewContext() *Context {
 	return c
 }
 
+// NewContextFromPairs returns a Context holding alternating keys and
+// values, as taken by Logger.With.  A non-string key, or a trailing
+// key with no value, is added as a value under the key "!BADKEY".
+func NewContextFromPairs(keysAndValues ...interface{}) *Context {
+	c := NewContext()
+	c.addPairs(keysAndValues)
+	return c
+}
+
 func (c *Context) Add(key string, value interface{}) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
+	if _, found := c.fields[key]; !found {
+		c.keys = append(c.keys, key)
+	}
 	c.fields[key] = value
 }
 
@@ -40,15 +59,94 @@ func (c *Context) Get(key string) (value interface{}, found bool) {
 	return
 }
 
+// GetString returns the value of key if it is a string.
+func (c *Context) GetString(key string) (value string, ok bool) {
+	v, _ := c.Get(key)
+	value, ok = v.(string)
+	return
+}
+
+// GetInt returns the value of key if it is an integer that fits in an
+// int.
+func (c *Context) GetInt(key string) (value int, ok bool) {
+	v, _ := c.Get(key)
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int8:
+		return int(n), true
+	case int16:
+		return int(n), true
+	case int32:
+		return int(n), true
+	case int64:
+		if int64(int(n)) == n {
+			return int(n), true
+		}
+	case uint:
+		if int(n) >= 0 {
+			return int(n), true
+		}
+	case uint8:
+		return int(n), true
+	case uint16:
+		return int(n), true
+	case uint32:
+		if int64(int(n)) == int64(n) {
+			return int(n), true
+		}
+	case uint64:
+		if int(n) >= 0 && uint64(int(n)) == n {
+			return int(n), true
+		}
+	}
+	return 0, false
+}
+
+// GetDuration returns the value of key if it is a time.Duration.
+func (c *Context) GetDuration(key string) (value time.Duration, ok bool) {
+	v, _ := c.Get(key)
+	value, ok = v.(time.Duration)
+	return
+}
+
+// GetTime returns the value of key if it is a time.Time.
+func (c *Context) GetTime(key string) (value time.Time, ok bool) {
+	v, _ := c.Get(key)
+	value, ok = v.(time.Time)
+	return
+}
+
+// Range calls f with each field in insertion order until f returns
+// false.  f is called on a snapshot of the fields, so it may modify the
+// Context.  Range of a nil Context does nothing.
+func (c *Context) Range(f func(key string, value interface{}) bool) {
+	if c == nil {
+		return
+	}
+
+	c.lock.RLock()
+	keys := make([]string, len(c.keys))
+	values := make([]interface{}, len(c.keys))
+	for i, key := range c.keys {
+		keys[i] = key
+		values[i] = c.fields[key]
+	}
+	c.lock.RUnlock()
+
+	for i, key := range keys {
+		if !f(key, values[i]) {
+			return
+		}
+	}
+}
+
+// Keys returns the keys of the Context's fields in insertion order.
 func (c *Context) Keys() []string {
 	c.lock.RLock()
 	defer c.lock.RUnlock()
-	keys := make([]string, len(c.fields))
-	i := 0
-	for k, _ := range c.fields {
-		keys[i] = k
-		i++
-	}
+	keys := make([]string, len(c.keys))
+	copy(keys, c.keys)
 	return keys
 }
 
@@ -61,5 +159,115 @@ func (c *Context) Len() int {
 func (c *Context) Remove(key string) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
+	if _, found := c.fields[key]; !found {
+		return
+	}
 	delete(c.fields, key)
+	for i, k := range c.keys {
+		if k == key {
+			c.keys = append(c.keys[:i], c.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// badKey is used for values in a key/value list that are not preceded
+// by a string key.
+const badKey = "!BADKEY"
+
+// addPairs adds alternating keys and values to the Context.  A
+// non-string key, or a trailing key with no value, is added as a value
+// under badKey.
+func (c *Context) addPairs(keysAndValues []interface{}) {
+	for len(keysAndValues) > 0 {
+		key, ok := keysAndValues[0].(string)
+		if !ok || len(keysAndValues) == 1 {
+			c.Add(badKey, keysAndValues[0])
+			keysAndValues = keysAndValues[1:]
+			continue
+		}
+
+		c.Add(key, keysAndValues[1])
+		keysAndValues = keysAndValues[2:]
+	}
+}
+
+// Clone returns a new Context with the same fields, in the same
+// order.  The values themselves are not copied.  Clone of a nil
+// Context is nil.
+func (c *Context) Clone() *Context {
+	if c == nil {
+		return nil
+	}
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	clone := &Context{
+		fields: make(map[string]interface{}, len(c.fields)),
+		keys:   make([]string, len(c.keys)),
+	}
+	copy(clone.keys, c.keys)
+	for key, value := range c.fields {
+		clone.fields[key] = value
+	}
+	return clone
+}
+
+// Merge returns a new Context holding the fields of c followed by
+// the fields of overrides.  Fields in overrides replace fields with the
+// same key in c, keeping c's order.  Either Context may be nil; if
+// both are, Merge returns nil.
+func (c *Context) Merge(overrides *Context) *Context {
+	if c == nil {
+		return overrides.Clone()
+	}
+
+	merged := c.Clone()
+	if overrides == nil {
+		return merged
+	}
+
+	overrides.lock.RLock()
+	defer overrides.lock.RUnlock()
+	for _, key := range overrides.keys {
+		if _, found := merged.fields[key]; !found {
+			merged.keys = append(merged.keys, key)
+		}
+		merged.fields[key] = overrides.fields[key]
+	}
+	return merged
+}
+
+type contextKey struct{}
+
+// AttachContext returns a copy of ctx carrying the fields of c, after
+// any fields already attached to ctx.  Fields in c replace attached
+// fields with the same key.  Logger methods that take a
+// context.Context, such as LogfCtx, add the attached fields to every
+// Log.
+func AttachContext(ctx context.Context, c *Context) context.Context {
+	if c == nil || c.Len() == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, contextKey{}, ContextFrom(ctx).Merge(c))
+}
+
+// AttachFields is like AttachContext with a Context holding
+// alternating keys and values.
+// Example:
+//
+// ctx = slogger.AttachFields(ctx, "requestId", id, "tenant", tenant)
+func AttachFields(ctx context.Context, keysAndValues ...interface{}) context.Context {
+	return AttachContext(ctx, NewContextFromPairs(keysAndValues...))
+}
+
+// ContextFrom returns the fields attached to ctx by AttachContext or
+// AttachFields, or nil if there are none.  The returned Context must
+// not be modified.
+func ContextFrom(ctx context.Context) *Context {
+	if ctx == nil {
+		return nil
+	}
+	c, _ := ctx.Value(contextKey{}).(*Context)
+	return c
 }
@@ -16,6 +16,7 @@ package slogger
 
 // enables level-filtering before a Log entry is created, avoiding the runtime.Caller invocation
 // return true if filter evaluation should continue
+// Logger.Enabled calls turbo filters with an empty messageFmt and no args
 type TurboFilter func(level Level, messageFmt string, args ...interface{}) bool
 
 func TurboLevelFilter(threshold Level) func(Level, string, ...interface{}) bool {
@@ -1,3 +1,3 @@
 module github.com/mongodb/slogger/v2/slogger
 
-go 1.18
+go 1.21
@@ -0,0 +1,100 @@
+// Copyright 2026 MongoDB, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slogger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// FormatLogJSON formats a Log as a single line JSON object terminated
+// by a newline.  Every Context field is included, in insertion order,
+// under the "context" key as a typed JSON value.  Values that cannot
+// be marshalled are rendered as strings with fmt.Sprint.  The
+// "traceId" and "spanId" keys are present only when set.
+//
+// FormatLogJSON can be passed to SetFormatLogFunc, or wrapped in a
+// FormatterFunc to apply it to a single Appender.
+func FormatLogJSON(log *Log) string {
+	buf := new(bytes.Buffer)
+	buf.WriteByte('{')
+	writeJSONField(buf, "timestamp", log.Timestamp.Format(time.RFC3339Nano), true)
+	writeJSONField(buf, "level", log.Level.Type(), false)
+	writeJSONField(buf, "prefix", log.Prefix, false)
+	writeJSONField(buf, "file", log.Filename, false)
+	writeJSONField(buf, "func", log.FuncName, false)
+	writeJSONField(buf, "line", log.Line, false)
+	if log.TraceID != "" {
+		writeJSONField(buf, "traceId", log.TraceID, false)
+	}
+	if log.SpanID != "" {
+		writeJSONField(buf, "spanId", log.SpanID, false)
+	}
+	if log.ErrorCode != NoErrorCode {
+		writeJSONField(buf, "errorCode", log.ErrorCode, false)
+	}
+	writeJSONField(buf, "message", log.Message(), false)
+
+	if log.Context != nil && log.Context.Len() > 0 {
+		buf.WriteString(`,"context":{`)
+		first := true
+		log.Context.Range(func(key string, value interface{}) bool {
+			writeJSONField(buf, key, value, first)
+			first = false
+			return true
+		})
+		buf.WriteByte('}')
+	}
+
+	buf.WriteString("}\n")
+	return buf.String()
+}
+
+func writeJSONField(buf *bytes.Buffer, key string, value interface{}, first bool) {
+	if !first {
+		buf.WriteByte(',')
+	}
+	buf.Write(marshalJSONValue(key))
+	buf.WriteByte(':')
+	buf.Write(marshalJSONValue(value))
+}
+
+func marshalJSONValue(value interface{}) []byte {
+	if err, ok := value.(error); ok {
+		if _, isMarshaler := value.(json.Marshaler); !isMarshaler {
+			value = err.Error()
+		}
+	}
+
+	encoded, err := encodeJSON(value)
+	if err != nil {
+		encoded, _ = encodeJSON(fmt.Sprint(value))
+	}
+	return encoded
+}
+
+// encodeJSON is json.Marshal without escaping HTML characters, which
+// would only make log lines harder to read.
+func encodeJSON(value interface{}) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
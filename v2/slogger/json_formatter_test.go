@@ -0,0 +1,127 @@
+// Copyright 2026 MongoDB, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slogger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatLogJSON(test *testing.T) {
+	ctxt := NewContext()
+	ctxt.Add("rsId", "backup_test")
+	ctxt.Add("shard", 3)
+	ctxt.Add("primary", true)
+	ctxt.Add("err", errors.New("connection reset"))
+
+	log := Log{
+		Prefix:     "agent.OplogTail",
+		Level:      WARN,
+		ErrorCode:  7,
+		Filename:   "oplog.go",
+		FuncName:   "TailOplog",
+		Line:       88,
+		Timestamp:  time.Date(2016, 2, 25, 14, 35, 10, 168000000, time.UTC),
+		MessageFmt: "Tail <restarted> %d times",
+		Args:       []interface{}{2},
+		Context:    ctxt,
+	}
+
+	received := FormatLogJSON(&log)
+	if !strings.HasSuffix(received, "}\n") || strings.Count(received, "\n") != 1 {
+		test.Fatalf("Expected a single newline terminated line. Received: `%v`", received)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(received), &decoded); err != nil {
+		test.Fatalf("Could not decode `%v`: %v", received, err)
+	}
+
+	expected := map[string]interface{}{
+		"timestamp": "2016-02-25T14:35:10.168Z",
+		"level":     "warn",
+		"prefix":    "agent.OplogTail",
+		"file":      "oplog.go",
+		"func":      "TailOplog",
+		"line":      float64(88),
+		"errorCode": float64(7),
+		"message":   "Tail <restarted> 2 times",
+	}
+	for key, value := range expected {
+		if decoded[key] != value {
+			test.Errorf("Expected %v to be %#v. Received: %#v", key, value, decoded[key])
+		}
+	}
+
+	decodedContext, ok := decoded["context"].(map[string]interface{})
+	if !ok {
+		test.Fatalf("Expected a context object. Received: `%v`", received)
+	}
+	expectedContext := map[string]interface{}{
+		"rsId":    "backup_test",
+		"shard":   float64(3),
+		"primary": true,
+		"err":     "connection reset",
+	}
+	for key, value := range expectedContext {
+		if decodedContext[key] != value {
+			test.Errorf("Expected context %v to be %#v. Received: %#v", key, value, decodedContext[key])
+		}
+	}
+}
+
+func TestFormatLogJSONWithoutContext(test *testing.T) {
+	log := Log{
+		Level:      INFO,
+		MessageFmt: "Nothing extra",
+	}
+
+	received := FormatLogJSON(&log)
+	if strings.Contains(received, "context") || strings.Contains(received, "errorCode") {
+		test.Errorf("Expected no context or errorCode keys. Received: `%v`", received)
+	}
+}
+
+func TestSetFormatLogFuncJSON(test *testing.T) {
+	SetFormatLogFunc(FormatLogJSON)
+	defer SetFormatLogFunc(FormatLog)
+
+	buffer := new(bytes.Buffer)
+	logger := &Logger{
+		Prefix:    "agent",
+		Appenders: []Appender{NewStringAppender(buffer)},
+	}
+
+	ctxt := NewContext()
+	ctxt.Add("user", "alice")
+	logger.LogfWithContext(INFO, "logged in", ctxt)
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(buffer.Bytes(), &decoded); err != nil {
+		test.Fatalf("Could not decode `%v`: %v", buffer.String(), err)
+	}
+
+	if decoded["file"] != "json_formatter_test.go" {
+		test.Errorf("Expected file to be json_formatter_test.go. Received: %v", decoded["file"])
+	}
+
+	if decoded["context"].(map[string]interface{})["user"] != "alice" {
+		test.Errorf("Expected context field user. Received: %v", buffer.String())
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2026 MongoDB, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slogger
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// A LevelRegistry holds level thresholds for Logger prefixes that can
+// be changed while the process runs.  Prefixes form a hierarchy split
+// by periods: the level set for "repl" applies to "repl.oplog" unless
+// a level is set for "repl.oplog" itself.  The level set for the empty
+// prefix applies to every prefix without a more specific level.
+//
+// Reads take no locks, so a LevelRegistry can be consulted on every
+// log call.
+type LevelRegistry struct {
+	levels atomic.Value // map[string]Level, replaced on every change
+
+	lock sync.Mutex // held while replacing levels
+}
+
+// NewLevelRegistry returns a LevelRegistry with rootLevel set for the
+// empty prefix.
+func NewLevelRegistry(rootLevel Level) *LevelRegistry {
+	registry := &LevelRegistry{}
+	registry.levels.Store(map[string]Level{"": rootLevel})
+	return registry
+}
+
+// normalizePrefix accepts "storage.*" as another way to write
+// "storage", and "*" for the empty prefix.
+func normalizePrefix(prefix string) string {
+	if prefix == "*" {
+		return ""
+	}
+	return strings.TrimSuffix(prefix, ".*")
+}
+
+// SetLevel sets the threshold for prefix and the prefixes below it
+// that have no level of their own.
+func (self *LevelRegistry) SetLevel(prefix string, level Level) {
+	self.update(func(levels map[string]Level) {
+		levels[normalizePrefix(prefix)] = level
+	})
+}
+
+// UnsetLevel removes the threshold set for prefix, so that the level
+// of its closest ancestor applies.  The empty prefix's level cannot be
+// removed.
+func (self *LevelRegistry) UnsetLevel(prefix string) {
+	prefix = normalizePrefix(prefix)
+	if prefix == "" {
+		return
+	}
+
+	self.update(func(levels map[string]Level) {
+		delete(levels, prefix)
+	})
+}
+
+// ReplaceLevels replaces every threshold that has been set with
+// levels in a single change.  The empty prefix keeps its level unless
+// levels sets one for it.
+func (self *LevelRegistry) ReplaceLevels(levels map[string]Level) {
+	self.update(func(current map[string]Level) {
+		root := current[""]
+		for prefix := range current {
+			delete(current, prefix)
+		}
+
+		current[""] = root
+		for prefix, level := range levels {
+			current[normalizePrefix(prefix)] = level
+		}
+	})
+}
+
+// EffectiveLevel returns the threshold that applies to prefix.
+func (self *LevelRegistry) EffectiveLevel(prefix string) Level {
+	levels := self.load()
+	for {
+		if level, found := levels[prefix]; found {
+			return level
+		}
+		if prefix == "" {
+			return levels[""]
+		}
+
+		if i := strings.LastIndexByte(prefix, '.'); i >= 0 {
+			prefix = prefix[:i]
+		} else {
+			prefix = ""
+		}
+	}
+}
+
+// Levels returns a copy of the thresholds that have been set, by
+// prefix.
+func (self *LevelRegistry) Levels() map[string]Level {
+	levels := self.load()
+	copied := make(map[string]Level, len(levels))
+	for prefix, level := range levels {
+		copied[prefix] = level
+	}
+	return copied
+}
+
+// Prefixes returns the prefixes that have thresholds set, sorted.
+func (self *LevelRegistry) Prefixes() []string {
+	levels := self.load()
+	prefixes := make([]string, 0, len(levels))
+	for prefix := range levels {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+	return prefixes
+}
+
+// Enabled reports whether a Log at level from a Logger with prefix
+// meets the threshold.
+func (self *LevelRegistry) Enabled(prefix string, level Level) bool {
+	return level >= self.EffectiveLevel(prefix)
+}
+
+// TurboFilter returns a TurboFilter for a Logger with the given prefix.
+// Prefer setting Logger.Levels, which applies to the Logger's children
+// with their own prefixes.
+func (self *LevelRegistry) TurboFilter(prefix string) TurboFilter {
+	return func(level Level, messageFmt string, args ...interface{}) bool {
+		return self.Enabled(prefix, level)
+	}
+}
+
+// Filter is a Filter that passes Logs meeting the threshold for their
+// Prefix.
+func (self *LevelRegistry) Filter(log *Log) bool {
+	return self.Enabled(log.Prefix, log.Level)
+}
+
+// LevelFilter returns a FilterAppender that appends to appender the
+// Logs that meet the threshold for their Prefix.
+func (self *LevelRegistry) LevelFilter(appender Appender) *FilterAppender {
+	return &FilterAppender{
+		Appender: appender,
+		Filter:   self.Filter,
+	}
+}
+
+func (self *LevelRegistry) load() map[string]Level {
+	return self.levels.Load().(map[string]Level)
+}
+
+// update replaces the levels with a modified copy, so that readers
+// never see a partial change.
+func (self *LevelRegistry) update(modify func(levels map[string]Level)) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	levels := self.Levels()
+	modify(levels)
+	self.levels.Store(levels)
+}
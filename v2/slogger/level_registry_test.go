@@ -0,0 +1,181 @@
+// Copyright 2026 MongoDB, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slogger
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestEffectiveLevel(test *testing.T) {
+	registry := NewLevelRegistry(INFO)
+	registry.SetLevel("repl", WARN)
+	registry.SetLevel("storage.*", DEBUG)
+	registry.SetLevel("storage.wt", ERROR)
+
+	expected := map[string]Level{
+		"":                INFO,
+		"network":         INFO,
+		"repl":            WARN,
+		"repl.oplog":      WARN,
+		"repl.oplog.tail": WARN,
+		"replica":         INFO,
+		"storage":         DEBUG,
+		"storage.journal": DEBUG,
+		"storage.wt":      ERROR,
+		"storage.wt.lsm":  ERROR,
+	}
+	for prefix, level := range expected {
+		if actual := registry.EffectiveLevel(prefix); actual != level {
+			test.Errorf("Expected %q to be at %v. Received: %v", prefix, level, actual)
+		}
+	}
+
+	registry.UnsetLevel("storage.wt")
+	registry.UnsetLevel("")
+	if level := registry.EffectiveLevel("storage.wt"); level != DEBUG {
+		test.Errorf("Expected storage.wt to inherit DEBUG. Received: %v", level)
+	}
+
+	if prefixes := registry.Prefixes(); !reflect.DeepEqual(prefixes, []string{"", "repl", "storage"}) {
+		test.Errorf("Unexpected prefixes: %v", prefixes)
+	}
+}
+
+func TestLoggerLevels(test *testing.T) {
+	registry := NewLevelRegistry(WARN)
+	counter := &countingAppender{}
+	logger := &Logger{
+		Prefix:    "repl",
+		Appenders: []Appender{counter},
+		Levels:    registry,
+	}
+	oplogLogger := logger.Child("oplog", nil)
+
+	logger.Logf(INFO, "Dropped")
+	oplogLogger.Logf(INFO, "Dropped")
+	if counter.count != 0 || oplogLogger.Enabled(INFO) {
+		test.Errorf("Expected INFO to be dropped. Appended: %d", counter.count)
+	}
+
+	// raising the level of "repl" applies to the child
+	registry.SetLevel("repl", DEBUG)
+	oplogLogger.Logf(INFO, "Logged")
+	if counter.count != 1 || !oplogLogger.Enabled(DEBUG) {
+		test.Errorf("Expected INFO to be logged. Appended: %d", counter.count)
+	}
+
+	registry.SetLevel("repl.oplog", ERROR)
+	oplogLogger.Logf(WARN, "Dropped")
+	logger.Logf(WARN, "Logged")
+	if counter.count != 2 {
+		test.Errorf("Expected only the parent's WARN log. Appended: %d", counter.count)
+	}
+}
+
+func TestErrorfBelowLevel(test *testing.T) {
+	counter := &countingAppender{}
+	logger := &Logger{
+		Appenders: []Appender{counter},
+		Levels:    NewLevelRegistry(INFO),
+	}
+
+	err := logger.Errorf(DEBUG, "Failed after %d attempts", 3)
+	if err == nil || err.Error() != "Failed after 3 attempts" {
+		test.Errorf("Expected the error to be returned. Received: %v", err)
+	}
+	if counter.count != 0 {
+		test.Errorf("Expected the DEBUG log to be dropped. Appended: %d", counter.count)
+	}
+}
+
+func TestErrorfBelowLevelTruncates(test *testing.T) {
+	SetMaxLogSize(200)
+	defer SetMaxLogSize(-1)
+
+	buffer := new(bytes.Buffer)
+	logger := &Logger{
+		Appenders: []Appender{NewStringAppender(buffer)},
+		Levels:    NewLevelRegistry(INFO),
+	}
+
+	message := strings.Repeat("x", 1000)
+	filtered := logger.Errorf(DEBUG, "%s", message)
+	logged := logger.Errorf(WARN, "%s", message)
+	if filtered.Error() != logged.Error() || len(filtered.Error()) >= len(message) {
+		test.Errorf("Expected the same truncated error whether or not the log is filtered. Received: %d and %d bytes",
+			len(filtered.Error()), len(logged.Error()))
+	}
+}
+
+func TestLevelRegistryFilters(test *testing.T) {
+	registry := NewLevelRegistry(INFO)
+	registry.SetLevel("noisy", ERROR)
+
+	turbo := registry.TurboFilter("noisy.child")
+	if turbo(WARN, "msg") || !turbo(ERROR, "msg") {
+		test.Errorf("Expected the turbo filter to use noisy's level")
+	}
+
+	counter := &countingAppender{}
+	appender := registry.LevelFilter(counter)
+	appender.Append(SimpleLog("noisy", WARN, NoErrorCode, 1, "Dropped"))
+	appender.Append(SimpleLog("quiet", WARN, NoErrorCode, 1, "Appended"))
+	if counter.count != 1 {
+		test.Errorf("Expected one log appended. Received: %d", counter.count)
+	}
+}
+
+func TestReplaceLevels(test *testing.T) {
+	registry := NewLevelRegistry(INFO)
+	registry.SetLevel("db", DEBUG)
+
+	registry.ReplaceLevels(map[string]Level{"http.*": WARN})
+	levels := registry.Levels()
+	if len(levels) != 2 || levels[""] != INFO || levels["http"] != WARN {
+		test.Errorf("Expected the root level to be kept and db's removed. Received: %v", levels)
+	}
+
+	registry.ReplaceLevels(map[string]Level{"*": ERROR})
+	levels = registry.Levels()
+	if len(levels) != 1 || levels[""] != ERROR {
+		test.Errorf("Expected only the replaced root level. Received: %v", levels)
+	}
+}
+
+func TestLevelRegistryConcurrency(test *testing.T) {
+	registry := NewLevelRegistry(INFO)
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				registry.SetLevel("storage", Level(j%int(OFF)))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				registry.EffectiveLevel("storage.wt")
+			}
+		}()
+	}
+	wg.Wait()
+}
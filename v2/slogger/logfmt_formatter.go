@@ -0,0 +1,136 @@
+// Copyright 2026 MongoDB, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slogger
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// FormatLogfmt formats a Log as a single logfmt line of key=value
+// pairs terminated by a newline.  Context fields follow the Log's own
+// fields in insertion order, and a field whose key is one of the Log's
+// own keys, such as "level" or "msg", is written as "context.level" or
+// "context.msg" so that it cannot override the Log's.  Values containing spaces, quotes, '=' or control
+// characters (such as the newlines in a Stackf message) are quoted
+// and escaped so that every Log is exactly one line.
+//
+// FormatLogfmt can be passed to SetFormatLogFunc, or wrapped in a
+// FormatterFunc to apply it to a single Appender.
+func FormatLogfmt(log *Log) string {
+	buf := new(bytes.Buffer)
+	writeLogfmtPair(buf, "time", log.Timestamp.Format(time.RFC3339Nano))
+	writeLogfmtPair(buf, "level", log.Level.Type())
+	writeLogfmtPair(buf, "prefix", log.Prefix)
+	writeLogfmtPair(buf, "file", log.Filename)
+	writeLogfmtPair(buf, "func", log.FuncName)
+	writeLogfmtPair(buf, "line", log.Line)
+	if log.TraceID != "" {
+		writeLogfmtPair(buf, "traceId", log.TraceID)
+	}
+	if log.SpanID != "" {
+		writeLogfmtPair(buf, "spanId", log.SpanID)
+	}
+	if log.ErrorCode != NoErrorCode {
+		writeLogfmtPair(buf, "errorCode", log.ErrorCode)
+	}
+	writeLogfmtPair(buf, "msg", log.Message())
+
+	log.Context.Range(func(key string, value interface{}) bool {
+		if logfmtReservedKeys[logfmtKey(key)] {
+			key = "context." + key
+		}
+		writeLogfmtPair(buf, key, value)
+		return true
+	})
+
+	buf.WriteByte('\n')
+	return buf.String()
+}
+
+// logfmtReservedKeys are the keys FormatLogfmt writes for a Log's own
+// fields.  logfmt parsers keep the last of duplicate keys.
+var logfmtReservedKeys = map[string]bool{
+	"time":      true,
+	"level":     true,
+	"prefix":    true,
+	"file":      true,
+	"func":      true,
+	"line":      true,
+	"traceId":   true,
+	"spanId":    true,
+	"errorCode": true,
+	"msg":       true,
+}
+
+func writeLogfmtPair(buf *bytes.Buffer, key string, value interface{}) {
+	if buf.Len() > 0 {
+		buf.WriteByte(' ')
+	}
+	buf.WriteString(logfmtKey(key))
+	buf.WriteByte('=')
+	buf.WriteString(logfmtValue(value))
+}
+
+// logfmtKey replaces the characters that cannot appear in an unquoted
+// logfmt key.
+func logfmtKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || !unicode.IsPrint(r) {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+func logfmtValue(value interface{}) string {
+	var str string
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		str = v
+	case error:
+		str = v.Error()
+	default:
+		str = fmt.Sprint(v)
+	}
+
+	if logfmtNeedsQuoting(str) {
+		return strconv.Quote(str)
+	}
+	return str
+}
+
+func logfmtNeedsQuoting(str string) bool {
+	if str == "" {
+		return true
+	}
+
+	for _, r := range str {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 MongoDB, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slogger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatLogfmt(test *testing.T) {
+	ctxt := NewContext()
+	ctxt.Add("rsId", "backup_test")
+	ctxt.Add("shard", 3)
+	ctxt.Add("query", `{"a": 1}`)
+	ctxt.Add("empty", "")
+
+	log := Log{
+		Prefix:     "agent.OplogTail",
+		Level:      INFO,
+		ErrorCode:  7,
+		Filename:   "oplog.go",
+		FuncName:   "TailOplog",
+		Line:       88,
+		Timestamp:  time.Date(2016, 2, 25, 14, 35, 10, 168000000, time.UTC),
+		MessageFmt: "Tail started on RsId: %v",
+		Args:       []interface{}{"backup_test"},
+		Context:    ctxt,
+	}
+
+	expected := `time=2016-02-25T14:35:10.168Z level=info prefix=agent.OplogTail file=oplog.go func=TailOplog line=88 errorCode=7 msg="Tail started on RsId: backup_test" rsId=backup_test shard=3 query="{\"a\": 1}" empty=""` + "\n"
+	received := FormatLogfmt(&log)
+	if received != expected {
+		test.Errorf("Improperly formatted log.\nExpected: `%v`\nReceived: `%v`", expected, received)
+	}
+}
+
+func TestFormatLogfmtReservedKeys(test *testing.T) {
+	log := SimpleLog("agent", WARN, NoErrorCode, 1, "Real message")
+	log.Context = NewContextFromPairs("level", "debug", "msg", "Fake message", "user", "alice")
+
+	received := FormatLogfmt(log)
+	if !strings.Contains(received, " level=warn ") || !strings.Contains(received, ` msg="Real message" `) {
+		test.Errorf("Expected the Log's own level and msg. Received: %s", received)
+	}
+	if !strings.HasSuffix(received, ` context.level=debug context.msg="Fake message" user=alice`+"\n") {
+		test.Errorf("Expected colliding Context fields to be renamed. Received: %s", received)
+	}
+}
+
+func TestFormatLogfmtStackf(test *testing.T) {
+	buffer := new(bytes.Buffer)
+	logger := &Logger{
+		Prefix:    "slogger.logfmt_test",
+		Appenders: []Appender{NewStringAppenderWithFormatter(buffer, FormatterFunc(FormatLogfmt))},
+	}
+
+	logger.Stackf(WARN, NewStackError("bad \"thing\""), "Failed with %d", 2)
+
+	received := buffer.String()
+	if strings.Count(received, "\n") != 1 || !strings.HasSuffix(received, "\n") {
+		test.Fatalf("Expected exactly one line. Received: `%v`", received)
+	}
+
+	if !strings.Contains(received, `msg="Failed with 2\nbad \"thing\"\n\tat `) {
+		test.Errorf("Expected an escaped multi-line message. Received: `%v`", received)
+	}
+
+	if !strings.Contains(received, "file=logfmt_formatter_test.go") {
+		test.Errorf("Expected the calling file. Received: `%v`", received)
+	}
+}
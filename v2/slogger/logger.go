@@ -15,8 +15,11 @@
 package slogger
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	stdlog "log"
 	"runtime"
 	"strings"
 	"sync"
@@ -36,6 +39,16 @@ type Log struct {
 	MessageFmt string
 	Args       []interface{}
 	Context    *Context
+
+	// TraceID and SpanID identify the trace span the Log was made in,
+	// if any.  Logger methods that take a context.Context set them
+	// with the function passed to SetTraceExtractor.
+	TraceID string
+	SpanID  string
+
+	// set by Preformat
+	preformatted    string
+	hasPreformatted bool
 }
 
 func SimpleLog(prefix string, level Level, errorCode ErrorCode, callerSkip int, messageFmt string, args ...interface{}) *Log {
@@ -104,11 +117,34 @@ func (self *Log) Message() string {
 	return getTruncatedMessage(fmt.Sprintf(self.MessageFmt, self.Args...))
 }
 
+// Preformat renders the Log with formatter now.  FormatWith then
+// returns the rendered text whatever formatter it is given, so
+// Appenders write the Log without formatting it again.  The Log must
+// not be modified after it is preformatted.
+func (self *Log) Preformat(formatter Formatter) {
+	self.preformatted = FormatWith(formatter, self)
+	self.hasPreformatted = true
+}
+
+// Preformatted returns the text rendered by Preformat, if it has been
+// called.
+func (self *Log) Preformatted() (string, bool) {
+	return self.preformatted, self.hasPreformatted
+}
+
 type Logger struct {
 	Prefix       string
 	Appenders    []Appender
 	StripDirs    int
 	TurboFilters []TurboFilter
+
+	// Context holds fields that are added to every Log.  Fields
+	// passed with an individual log call take precedence.
+	Context *Context
+
+	// Levels, if set, drops Logs below the threshold it holds for
+	// Prefix before the turbo filters are consulted.
+	Levels *LevelRegistry
 }
 
 // Log a message and a level to a logger instance. This returns a
@@ -127,6 +163,173 @@ func (self *Logger) LogfWithErrorCodeAndContext(level Level, errorCode ErrorCode
 	return self.logf(level, errorCode, messageFmt, context, args...)
 }
 
+// Tracef, Debugf, Infof, Warnf, ErrorLogf and Fatalf are shorthands
+// for Logf at the corresponding level.  The ERROR level shorthand is
+// named ErrorLogf as Errorf already returns an error.
+func (self *Logger) Tracef(messageFmt string, args ...interface{}) (*Log, []error) {
+	return self.logf(TRACE, NoErrorCode, messageFmt, nil, args...)
+}
+
+func (self *Logger) Debugf(messageFmt string, args ...interface{}) (*Log, []error) {
+	return self.logf(DEBUG, NoErrorCode, messageFmt, nil, args...)
+}
+
+func (self *Logger) Infof(messageFmt string, args ...interface{}) (*Log, []error) {
+	return self.logf(INFO, NoErrorCode, messageFmt, nil, args...)
+}
+
+func (self *Logger) Warnf(messageFmt string, args ...interface{}) (*Log, []error) {
+	return self.logf(WARN, NoErrorCode, messageFmt, nil, args...)
+}
+
+func (self *Logger) ErrorLogf(messageFmt string, args ...interface{}) (*Log, []error) {
+	return self.logf(ERROR, NoErrorCode, messageFmt, nil, args...)
+}
+
+// Fatalf logs at the FATAL level.  It does not exit the process.
+func (self *Logger) Fatalf(messageFmt string, args ...interface{}) (*Log, []error) {
+	return self.logf(FATAL, NoErrorCode, messageFmt, nil, args...)
+}
+
+// Enabled reports whether a Log at the given level could be appended
+// by this logger.  Use it to skip preparing expensive arguments.  It
+// returns false if a turbo filter rejects the level (turbo filters are
+// passed an empty messageFmt) or if no appender would accept it.
+// Appenders that do not implement LevelEnabler are assumed to accept
+// every level.
+// Example:
+//
+//	if logger.Enabled(slogger.DEBUG) {
+//	    logger.Debugf("Routing table: %v", table.Dump())
+//	}
+func (self *Logger) Enabled(level Level) bool {
+	if self.Levels != nil && !self.Levels.Enabled(self.Prefix, level) {
+		return false
+	}
+
+	for _, filter := range self.TurboFilters {
+		if filter(level, "") == false {
+			return false
+		}
+	}
+
+	for _, appender := range self.Appenders {
+		if AppenderEnabled(appender, level) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// With returns a copy of the logger that adds the given alternating
+// keys and values to every Log, after any fields the logger already
+// carries.
+// Example:
+//
+// connLogger := logger.With("remote", conn.RemoteAddr(), "shard", shardId)
+// connLogger.Infow("Connection accepted")
+func (self *Logger) With(keysAndValues ...interface{}) *Logger {
+	return self.Child("", NewContextFromPairs(keysAndValues...))
+}
+
+// Child returns a logger that shares this logger's appenders, turbo
+// filters, StripDirs and Levels.  Its prefix is this logger's prefix joined
+// with prefixSuffix by a period (e.g. "server" and "repl" give
+// "server.repl"); an empty prefixSuffix keeps the prefix as is.  Every
+// Log from the child carries this logger's Context merged with
+// context, with context's fields taking precedence.
+//
+// Appending to the child's Appenders or TurboFilters does not affect
+// this logger.
+func (self *Logger) Child(prefixSuffix string, context *Context) *Logger {
+	return &Logger{
+		Prefix:       joinPrefix(self.Prefix, prefixSuffix),
+		Appenders:    self.Appenders[:len(self.Appenders):len(self.Appenders)],
+		StripDirs:    self.StripDirs,
+		TurboFilters: self.TurboFilters[:len(self.TurboFilters):len(self.TurboFilters)],
+		Context:      self.Context.Merge(context),
+		Levels:       self.Levels,
+	}
+}
+
+func joinPrefix(prefix, suffix string) string {
+	if prefix == "" {
+		return suffix
+	}
+	if suffix == "" {
+		return prefix
+	}
+	return prefix + "." + suffix
+}
+
+// Logw logs msg with alternating keys and values that are stored, in
+// order, in the Log's Context.  Unlike Logf, msg is not a format
+// string.
+// Example:
+//
+// logger.Logw(slogger.INFO, "Chunk migrated", "ns", ns, "bytes", n)
+func (self *Logger) Logw(level Level, msg string, keysAndValues ...interface{}) (*Log, []error) {
+	return self.logw(level, msg, keysAndValues)
+}
+
+func (self *Logger) Tracew(msg string, keysAndValues ...interface{}) (*Log, []error) {
+	return self.logw(TRACE, msg, keysAndValues)
+}
+
+func (self *Logger) Debugw(msg string, keysAndValues ...interface{}) (*Log, []error) {
+	return self.logw(DEBUG, msg, keysAndValues)
+}
+
+func (self *Logger) Infow(msg string, keysAndValues ...interface{}) (*Log, []error) {
+	return self.logw(INFO, msg, keysAndValues)
+}
+
+func (self *Logger) Warnw(msg string, keysAndValues ...interface{}) (*Log, []error) {
+	return self.logw(WARN, msg, keysAndValues)
+}
+
+func (self *Logger) Errorw(msg string, keysAndValues ...interface{}) (*Log, []error) {
+	return self.logw(ERROR, msg, keysAndValues)
+}
+
+// Fatalw logs at the FATAL level.  It does not exit the process.
+func (self *Logger) Fatalw(msg string, keysAndValues ...interface{}) (*Log, []error) {
+	return self.logw(FATAL, msg, keysAndValues)
+}
+
+func (self *Logger) logw(level Level, msg string, keysAndValues []interface{}) (*Log, []error) {
+	var context *Context
+	if len(keysAndValues) > 0 {
+		context = NewContextFromPairs(keysAndValues...)
+	}
+	return self.logf(level, NoErrorCode, escapeFormat(msg), context)
+}
+
+// LogfCtx is like Logf, but also adds the fields attached to ctx by
+// AttachContext or AttachFields.  The Logger's own fields come first,
+// and attached fields replace them where keys are the same.
+func (self *Logger) LogfCtx(ctx context.Context, level Level, messageFmt string, args ...interface{}) (*Log, []error) {
+	return self.logfCtx(ctx, level, NoErrorCode, messageFmt, ContextFrom(ctx), args...)
+}
+
+// LogwCtx is like Logw, but also adds the fields attached to ctx.
+// Fields are taken from the Logger, then ctx, then keysAndValues, with
+// later fields replacing earlier ones with the same key.
+func (self *Logger) LogwCtx(ctx context.Context, level Level, msg string, keysAndValues ...interface{}) (*Log, []error) {
+	fields := ContextFrom(ctx)
+	if len(keysAndValues) > 0 {
+		fields = fields.Merge(NewContextFromPairs(keysAndValues...))
+	}
+	return self.logfCtx(ctx, level, NoErrorCode, escapeFormat(msg), fields)
+}
+
+// escapeFormat escapes msg so that it can be used as a messageFmt
+// without any arguments.
+func escapeFormat(msg string) string {
+	return strings.ReplaceAll(msg, "%", "%%")
+}
+
 // Log and return a formatted error string.
 // Example:
 //
@@ -159,6 +362,10 @@ func (self *Logger) ErrorfWithContext(level Level, messageFmt string, context *C
 
 func (self *Logger) ErrorfWithErrorCodeAndContext(level Level, errorCode ErrorCode, messageFmt string, context *Context, args ...interface{}) error {
 	log, _ := self.logf(level, errorCode, messageFmt, context, args...)
+	if log == nil {
+		// filtered out, but the error is still returned
+		log = &Log{MessageFmt: messageFmt, Args: args}
+	}
 	return ErrorWithCode{errorCode, errors.New(log.Message())}
 }
 
@@ -188,6 +395,80 @@ func (self *Logger) StackfWithErrorCodeAndContext(level Level, errorCode ErrorCo
 	return self.logf(level, errorCode, messageFmt, context, args...)
 }
 
+// LogWriter is an io.Writer that logs each line written to it through
+// a Logger at a fixed level.  It is safe for concurrent use.
+type LogWriter struct {
+	logger  *Logger
+	level   Level
+	partial []byte // an unterminated line, protected by lock
+	lock    sync.Mutex
+}
+
+// Writer returns a LogWriter that logs each line written to it at
+// level.  Use Child to give those logs their own prefix.  The Logs are
+// attributed to the code that called the standard library's log
+// package, rather than to the log package itself.
+// Example:
+//
+//	server := &http.Server{
+//	    ErrorLog: stdlog.New(logger.Child("http", nil).Writer(slogger.WARN), "", 0),
+//	}
+func (self *Logger) Writer(level Level) *LogWriter {
+	return &LogWriter{logger: self, level: level}
+}
+
+// StdLogger returns a standard library *log.Logger that logs each
+// line through this Logger at level.
+func (self *Logger) StdLogger(level Level) *stdlog.Logger {
+	return stdlog.New(self.Writer(level), "", 0)
+}
+
+// Write logs every complete line in p.  A trailing partial line is
+// held until the rest of it is written or until Flush is called.
+func (self *LogWriter) Write(p []byte) (int, error) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	self.partial = append(self.partial, p...)
+	var errs []error
+	for {
+		idx := bytes.IndexByte(self.partial, '\n')
+		if idx < 0 {
+			break
+		}
+		line := self.partial[:idx]
+		self.partial = self.partial[idx+1:]
+		errs = append(errs, self.logLine(line)...)
+	}
+
+	if len(errs) > 0 {
+		return len(p), errs[0]
+	}
+	return len(p), nil
+}
+
+// Flush logs any partial line that is being held.
+func (self *LogWriter) Flush() error {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	line := self.partial
+	self.partial = nil
+	if errs := self.logLine(line); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+func (self *LogWriter) logLine(line []byte) []error {
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	if len(line) == 0 {
+		return nil
+	}
+	_, errs := self.logger.logfFrom(stdLogCaller, nil, self.level, NoErrorCode, escapeFormat(string(line)), nil)
+	return errs
+}
+
 var ignoredFileNames = []string{"logger.go"}
 
 // Add a file to the list of file names that slogger will skip when it identifies the source
@@ -243,16 +524,42 @@ func nonSloggerCaller() (pc uintptr, file string, line int, ok bool) {
 	return 0, "", 0, false
 }
 
+// stdLogCaller is nonSloggerCaller for logs written through a
+// LogWriter.  It also skips the frames of the standard library's log
+// package, which are identified by function rather than by file name
+// so that no other log/log.go is skipped.
+func stdLogCaller() (pc uintptr, file string, line int, ok bool) {
+	pcs := make([]uintptr, 100)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		if frame.PC != 0 && !containsAnyIgnoredFilename(frame.File) && !strings.HasPrefix(frame.Function, "log.") {
+			return frame.PC, frame.File, frame.Line, true
+		}
+		if !more {
+			return 0, "", 0, false
+		}
+	}
+}
+
 func (self *Logger) logf(level Level, errorCode ErrorCode, messageFmt string, context *Context, args ...interface{}) (*Log, []error) {
-	var errors []error
+	return self.logfCtx(nil, level, errorCode, messageFmt, context, args...)
+}
 
-	for _, filter := range self.TurboFilters {
-		if filter(level, messageFmt, args) == false {
-			return nil, errors
-		}
+// logfCtx is logf that also takes trace and span IDs from ctx, which
+// may be nil.
+func (self *Logger) logfCtx(ctx context.Context, level Level, errorCode ErrorCode, messageFmt string, context *Context, args ...interface{}) (*Log, []error) {
+	return self.logfFrom(nonSloggerCaller, ctx, level, errorCode, messageFmt, context, args...)
+}
+
+// logfFrom is logfCtx that attributes the Log to the frame found by
+// caller.
+func (self *Logger) logfFrom(caller func() (uintptr, string, int, bool), ctx context.Context, level Level, errorCode ErrorCode, messageFmt string, context *Context, args ...interface{}) (*Log, []error) {
+	if !self.allows(level, messageFmt, args) {
+		return nil, nil
 	}
 
-	pc, file, line, ok := nonSloggerCaller()
+	pc, file, line, ok := caller()
 	if ok == false {
 		return nil, []error{fmt.Errorf("Failed to find the calling method.")}
 	}
@@ -268,17 +575,48 @@ func (self *Logger) logf(level Level, errorCode ErrorCode, messageFmt string, co
 		Timestamp:  time.Now(),
 		MessageFmt: messageFmt,
 		Args:       args,
-		Context:    context,
+		Context:    self.Context.Merge(context),
+	}
+	log.TraceID, log.SpanID = ExtractTrace(ctx)
+
+	return log, self.appendToAll(log)
+}
+
+// AppendLog passes a Log that was built by the caller, rather than by
+// one of the Logf methods, to the Logger's Appenders if its Levels and
+// TurboFilters allow it.  It is for adapters, such as slog_bridge's
+// Handler, that receive logs from another logging API.
+func (self *Logger) AppendLog(log *Log) []error {
+	if !self.allows(log.Level, log.MessageFmt, log.Args) {
+		return nil
+	}
+	return self.appendToAll(log)
+}
+
+// allows reports whether the Logger's Levels and TurboFilters allow a
+// log, before it is built.
+func (self *Logger) allows(level Level, messageFmt string, args []interface{}) bool {
+	if self.Levels != nil && !self.Levels.Enabled(self.Prefix, level) {
+		return false
 	}
 
+	for _, filter := range self.TurboFilters {
+		if filter(level, messageFmt, args) == false {
+			return false
+		}
+	}
+	return true
+}
+
+func (self *Logger) appendToAll(log *Log) []error {
+	var errors []error
 	for _, appender := range self.Appenders {
 		if err := appender.Append(log); err != nil {
 			error := fmt.Errorf("Error appending. Appender: %T Error: %v", appender, err)
 			errors = append(errors, error)
 		}
 	}
-
-	return log, errors
+	return errors
 }
 
 type Level uint8
@@ -374,6 +712,12 @@ func (self *StackError) Error() string {
 	return fmt.Sprintf("%s\n\t%s", self.Message, strings.Join(self.Stacktrace, "\n\t"))
 }
 
+// StripDirectories trims filepath down to its base name and the
+// toKeep directories above it, as Logger does for its StripDirs.
+func StripDirectories(filepath string, toKeep int) string {
+	return stripDirectories(filepath, toKeep)
+}
+
 func stripDirectories(filepath string, toKeep int) string {
 	var idxCutoff int
 
@@ -20,10 +20,12 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"reflect"
 	"regexp"
 	"sort"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestLevels(test *testing.T) {
@@ -84,6 +86,67 @@ func TestLog(test *testing.T) {
 	}
 }
 
+func TestPerAppenderFormatter(test *testing.T) {
+	textBuffer := new(bytes.Buffer)
+	jsonBuffer := new(bytes.Buffer)
+	logger := &Logger{
+		Prefix: "agent.OplogTail",
+		Appenders: []Appender{
+			NewStringAppender(textBuffer),
+			NewStringAppenderWithFormatter(jsonBuffer, FormatterFunc(FormatLogJSON)),
+		},
+	}
+
+	logger.Logf(WARN, "Written twice")
+
+	if !strings.Contains(textBuffer.String(), "[agent.OplogTail.warn]") {
+		test.Errorf("Expected text output. Received: `%v`", textBuffer.String())
+	}
+
+	if !strings.Contains(jsonBuffer.String(), `"message":"Written twice"`) {
+		test.Errorf("Expected JSON output. Received: `%v`", jsonBuffer.String())
+	}
+}
+
+func TestAppendBatch(test *testing.T) {
+	logs := []*Log{
+		SimpleLog("batch", WARN, NoErrorCode, 1, "first"),
+		SimpleLog("batch", WARN, NoErrorCode, 1, "second"),
+	}
+
+	buffer := new(bytes.Buffer)
+	if errs := AppendBatch(NewStringAppender(buffer), logs); len(errs) != 0 {
+		test.Errorf("Unexpected errors: %v", errs)
+	}
+	if lines := strings.Split(buffer.String(), "\n"); len(lines) != 3 ||
+		!strings.HasSuffix(lines[0], "first") || !strings.HasSuffix(lines[1], "second") {
+		test.Errorf("Expected both logs to be written in order. Received: `%v`", buffer.String())
+	}
+
+	// Appenders that are not BatchAppenders get one log at a time
+	counter := &countingAppender{}
+	if errs := AppendBatch(counter, logs); len(errs) != 0 {
+		test.Errorf("Unexpected errors: %v", errs)
+	}
+	if counter.count != 2 {
+		test.Errorf("Expected 2 appends. Received: %d", counter.count)
+	}
+}
+
+func TestPreformat(test *testing.T) {
+	log := SimpleLog("pre", WARN, NoErrorCode, 1, "Rendered once")
+	log.Preformat(FormatterFunc(FormatLogJSON))
+
+	formatted, ok := log.Preformatted()
+	if !ok || !strings.Contains(formatted, `"message":"Rendered once"`) {
+		test.Errorf("Expected JSON to be preformatted. Received: %v %q", ok, formatted)
+	}
+
+	if text := FormatWith(FormatterFunc(FormatLog), log); text != formatted {
+		test.Errorf("Expected the preformatted text. Received: %q", text)
+	}
+}
+
 type countingAppender struct {
 	count int
 }
@@ -134,6 +197,158 @@ func TestFilterOff(test *testing.T) {
 	}
 }
 
+func TestLevelMethods(test *testing.T) {
+	logger := &Logger{}
+
+	methods := map[Level]func(string, ...interface{}) (*Log, []error){
+		TRACE: logger.Tracef,
+		DEBUG: logger.Debugf,
+		INFO:  logger.Infof,
+		WARN:  logger.Warnf,
+		ERROR: logger.ErrorLogf,
+		FATAL: logger.Fatalf,
+	}
+
+	for level, method := range methods {
+		log, _ := method("%v", level)
+		if log.Level != level || log.Message() != level.String() {
+			test.Errorf("Expected a %v log. Received: %v %v", level, log.Level, log.Message())
+		}
+		if log.FuncName != "TestLevelMethods" {
+			test.Errorf("Expected caller to be TestLevelMethods. Received: %v", log.FuncName)
+		}
+	}
+}
+
+func TestEnabled(test *testing.T) {
+	counter := &countingAppender{}
+	logger := &Logger{
+		Appenders: []Appender{LevelFilter(WARN, counter)},
+	}
+
+	if logger.Enabled(INFO) || !logger.Enabled(WARN) {
+		test.Errorf("Expected Enabled to respect the LevelFilter threshold")
+	}
+
+	logger.Appenders = append(logger.Appenders, LevelFilter(DEBUG, counter))
+	if !logger.Enabled(DEBUG) || logger.Enabled(TRACE) {
+		test.Errorf("Expected Enabled if any appender accepts the level")
+	}
+
+	logger.TurboFilters = []TurboFilter{TurboLevelFilter(ERROR)}
+	if logger.Enabled(WARN) || !logger.Enabled(ERROR) {
+		test.Errorf("Expected Enabled to respect turbo filters")
+	}
+
+	custom := &FilterAppender{Appender: counter, Filter: func(log *Log) bool { return false }}
+	if !(&Logger{Appenders: []Appender{custom}}).Enabled(TRACE) {
+		test.Errorf("Expected a custom Filter to be assumed enabled")
+	}
+
+	if (&Logger{}).Enabled(FATAL) {
+		test.Errorf("Expected a logger without appenders to not be enabled")
+	}
+}
+
+func TestStdLogger(test *testing.T) {
+	appender := &retainingAppender{}
+	logger := &Logger{
+		Prefix:    "server",
+		Appenders: []Appender{appender},
+	}
+
+	stdLogger := logger.Child("http", nil).StdLogger(WARN)
+	stdLogger.Printf("http: TLS handshake error from %s: EOF", "10.0.0.1:5555")
+	stdLogger.Print("first\nsecond")
+
+	if len(appender.logs) != 3 {
+		test.Fatalf("Expected one log per line. Received: %d", len(appender.logs))
+	}
+
+	log := appender.logs[0]
+	if log.Level != WARN || log.Prefix != "server.http" {
+		test.Errorf("Expected a server.http WARN log. Received: %v %v", log.Prefix, log.Level)
+	}
+
+	if log.Message() != "http: TLS handshake error from 10.0.0.1:5555: EOF" {
+		test.Errorf("Unexpected message: %v", log.Message())
+	}
+
+	if log.Filename != "logger_test.go" || log.FuncName != "TestStdLogger" {
+		test.Errorf("Expected the caller of the log package. Received: %v:%v", log.Filename, log.FuncName)
+	}
+
+	if appender.logs[1].Message() != "first" || appender.logs[2].Message() != "second" {
+		test.Errorf("Expected multi-line output to be split into logs")
+	}
+}
+
+func TestWriterDoesNotIgnoreOtherLogFiles(test *testing.T) {
+	(&Logger{}).Writer(INFO)
+
+	if containsAnyIgnoredFilename("/src/example.com/app/log/log.go") {
+		test.Errorf("Expected only the standard library's log package to be skipped, and only by a LogWriter")
+	}
+}
+
+func TestLogWriterPartialLines(test *testing.T) {
+	appender := &retainingAppender{}
+	logger := &Logger{Appenders: []Appender{appender}}
+	writer := logger.Writer(INFO)
+
+	writer.Write([]byte("100% of "))
+	if len(appender.logs) != 0 {
+		test.Fatalf("Expected a partial line to be held")
+	}
+
+	writer.Write([]byte("a line\r\nand a partial one"))
+	if len(appender.logs) != 1 || appender.logs[0].Message() != "100% of a line" {
+		test.Fatalf("Expected a completed line to be logged. Received: %v", appender.logs)
+	}
+
+	if err := writer.Flush(); err != nil {
+		test.Fatalf("Flush returned an error: %v", err)
+	}
+	if len(appender.logs) != 2 || appender.logs[1].Message() != "and a partial one" {
+		test.Errorf("Expected Flush to log the partial line. Received: %v", appender.logs)
+	}
+}
+
+func TestAppendLog(test *testing.T) {
+	appender := &retainingAppender{}
+	logger := &Logger{
+		Prefix:    "bridge",
+		Appenders: []Appender{appender},
+		Levels:    NewLevelRegistry(INFO),
+		TurboFilters: []TurboFilter{func(level Level, messageFmt string, args ...interface{}) bool {
+			return messageFmt != "Filtered"
+		}},
+	}
+
+	logger.AppendLog(&Log{Prefix: "bridge", Level: DEBUG, MessageFmt: "Below level"})
+	logger.AppendLog(&Log{Prefix: "bridge", Level: WARN, MessageFmt: "Filtered"})
+	if errs := logger.AppendLog(&Log{Prefix: "bridge", Level: WARN, MessageFmt: "Appended"}); len(errs) != 0 {
+		test.Errorf("Unexpected errors: %v", errs)
+	}
+
+	if len(appender.logs) != 1 || appender.logs[0].Message() != "Appended" {
+		test.Errorf("Expected only the allowed log to be appended. Received: %v", appender.logs)
+	}
+}
+
+type retainingAppender struct {
+	logs []*Log
+}
+
+func (self *retainingAppender) Append(log *Log) error {
+	self.logs = append(self.logs, log)
+	return nil
+}
+
+func (self *retainingAppender) Flush() error {
+	return nil
+}
+
 func TestStacktrace(test *testing.T) {
 	stacktrace := NewStackError("").Stacktrace
 	if match, _ := regexp.MatchString("^at v2/slogger/logger_test.go:\\d+", stacktrace[0]); match == false {
@@ -273,6 +488,98 @@ func TestContext(t *testing.T) {
 	}
 }
 
+func TestContextCloneAndMerge(t *testing.T) {
+	base := NewContext()
+	base.Add("a", 1)
+	base.Add("b", 2)
+
+	clone := base.Clone()
+	clone.Add("c", 3)
+	if base.Len() != 2 || clone.Len() != 3 {
+		t.Errorf("Expected Clone to copy the fields. Base: %v Clone: %v", base.Keys(), clone.Keys())
+	}
+
+	overrides := NewContext()
+	overrides.Add("c", 4)
+	overrides.Add("a", 5)
+	merged := base.Merge(overrides)
+	assertContextFields(t, merged, []string{"a", "b", "c"}, []interface{}{5, 2, 4})
+	assertContextFields(t, base, []string{"a", "b"}, []interface{}{1, 2})
+
+	if (*Context)(nil).Clone() != nil || (*Context)(nil).Merge(nil) != nil {
+		t.Errorf("Expected Clone and Merge of nil Contexts to be nil")
+	}
+	assertContextFields(t, (*Context)(nil).Merge(base), []string{"a", "b"}, []interface{}{1, 2})
+}
+
+func TestContextRange(t *testing.T) {
+	ctxt := NewContextFromPairs("c", 1, "a", 2, "b", 3)
+
+	var keys []string
+	ctxt.Range(func(key string, value interface{}) bool {
+		keys = append(keys, key)
+		// modifying the Context while ranging over it is allowed
+		ctxt.Add("d", 4)
+		return key != "a"
+	})
+	if !reflect.DeepEqual(keys, []string{"c", "a"}) {
+		t.Errorf("Expected to range over c and a in order. Received: %v", keys)
+	}
+
+	(*Context)(nil).Range(func(string, interface{}) bool {
+		t.Errorf("Expected no fields in a nil Context")
+		return true
+	})
+}
+
+func TestContextTypedGetters(t *testing.T) {
+	now := time.Now()
+	ctxt := NewContextFromPairs(
+		"name", "oplog",
+		"count", int64(42),
+		"unsigned", uint8(7),
+		"elapsed", 3*time.Second,
+		"at", now,
+	)
+
+	if value, ok := ctxt.GetString("name"); !ok || value != "oplog" {
+		t.Errorf("GetString: %v %v", value, ok)
+	}
+	if _, ok := ctxt.GetString("count"); ok {
+		t.Errorf("Expected GetString of an int to fail")
+	}
+	if value, ok := ctxt.GetInt("count"); !ok || value != 42 {
+		t.Errorf("GetInt: %v %v", value, ok)
+	}
+	if value, ok := ctxt.GetInt("unsigned"); !ok || value != 7 {
+		t.Errorf("GetInt of a uint8: %v %v", value, ok)
+	}
+	if _, ok := ctxt.GetInt("missing"); ok {
+		t.Errorf("Expected GetInt of a missing key to fail")
+	}
+	if value, ok := ctxt.GetDuration("elapsed"); !ok || value != 3*time.Second {
+		t.Errorf("GetDuration: %v %v", value, ok)
+	}
+	if value, ok := ctxt.GetTime("at"); !ok || !value.Equal(now) {
+		t.Errorf("GetTime: %v %v", value, ok)
+	}
+}
+
+func TestContextSnapshot(t *testing.T) {
+	logger := &Logger{Appenders: []Appender{NewStringAppender(new(bytes.Buffer))}}
+
+	ctxt := NewContext()
+	ctxt.Add("state", "before")
+	log, errs := logger.LogfWithContext(WARN, "Snapshot", ctxt)
+	if len(errs) != 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+
+	ctxt.Add("state", "after")
+	ctxt.Add("extra", true)
+	assertContextFields(t, log.Context, []string{"state"}, []interface{}{"before"})
+}
+
 func TestTruncation(t *testing.T) {
 	const logFilename = "logger_test.output"
 	logfile, err := os.Create(logFilename)
@@ -0,0 +1,97 @@
+// Copyright 2026 MongoDB, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package network_appender
+
+import (
+	"fmt"
+)
+
+type ClosedError struct{}
+
+func (ClosedError) Error() string {
+	return "network_appender: Appender is closed"
+}
+
+func IsClosedError(err error) bool {
+	_, ok := err.(ClosedError)
+	return ok
+}
+
+type DialError struct {
+	Address string
+	Err     error
+}
+
+func (self DialError) Error() string {
+	return fmt.Sprintf(
+		"network_appender: Failed to connect to %s: %s",
+		self.Address,
+		self.Err.Error(),
+	)
+}
+
+func IsDialError(err error) bool {
+	_, ok := err.(DialError)
+	return ok
+}
+
+type DroppedLogsError struct {
+	Count int
+}
+
+func (self DroppedLogsError) Error() string {
+	return fmt.Sprintf("network_appender: Dropped %d logs", self.Count)
+}
+
+func IsDroppedLogsError(err error) bool {
+	_, ok := err.(DroppedLogsError)
+	return ok
+}
+
+type NotConnectedError struct {
+	Address  string
+	Buffered int
+}
+
+func (self NotConnectedError) Error() string {
+	return fmt.Sprintf(
+		"network_appender: Not connected to %s with %d logs buffered",
+		self.Address,
+		self.Buffered,
+	)
+}
+
+func IsNotConnectedError(err error) bool {
+	_, ok := err.(NotConnectedError)
+	return ok
+}
+
+type WriteError struct {
+	Address string
+	Err     error
+}
+
+func (self WriteError) Error() string {
+	return fmt.Sprintf(
+		"network_appender: Failed to write to %s: %s",
+		self.Address,
+		self.Err.Error(),
+	)
+}
+
+func IsWriteError(err error) bool {
+	_, ok := err.(WriteError)
+	return ok
+}
@@ -0,0 +1,495 @@
+// Copyright 2026 MongoDB, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package network_appender provides a slogger Appender that streams
+// formatted logs to a collector over TCP or a Unix socket,
+// reconnecting when the connection drops.
+
+package network_appender
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mongodb/slogger/v2/slogger"
+	"github.com/mongodb/slogger/v2/slogger/queue"
+)
+
+type Framing int
+
+const (
+	// NewlineFraming terminates each formatted log with a newline.
+	NewlineFraming Framing = iota
+	// LengthPrefixFraming precedes each formatted log (without a
+	// trailing newline) by its length as a 4 byte big-endian integer.
+	LengthPrefixFraming
+)
+
+type NetworkAppender struct {
+	// These fields should not need to change
+	network      string
+	address      string
+	framing      Framing
+	dialTimeout  time.Duration
+	writeTimeout time.Duration
+	minBackoff   time.Duration
+	maxBackoff   time.Duration
+	errHandler   func(error)
+
+	lock sync.Mutex
+
+	// These fields can change and the lock should be held when
+	// reading or writing to them after construction of the
+	// NetworkAppender struct
+	formatter    slogger.Formatter
+	conn         net.Conn     // nil until reconnected and buffered logs are sent
+	buffer       *queue.Queue // framed messages awaiting a connection
+	dropped      int          // messages dropped and not yet reported
+	reconnecting bool
+	closed       bool
+
+	closeCh chan struct{}
+}
+
+type networkAppenderBuilder struct {
+	network      string
+	address      string
+	framing      Framing
+	bufferSize   int
+	dialTimeout  time.Duration
+	writeTimeout time.Duration
+	minBackoff   time.Duration
+	maxBackoff   time.Duration
+	errHandler   func(error)
+	formatter    slogger.Formatter
+}
+
+// NewBuilder returns a new networkAppenderBuilder.  You can directly
+// call Build() to create a new NetworkAppender, or configure
+// additional options first.
+//
+// network and address are as for net.Dial, and network should be a
+// stream network such as "tcp" or "unix".
+//
+// While disconnected, up to bufferSize formatted logs are buffered.
+// When the buffer is full the oldest log is dropped to make room.
+// Buffered logs are sent, in order, once the connection is
+// re-established.
+//
+// As the connection is made in the background, an errHandler can be
+// provided that will be called with connection errors and with a
+// DroppedLogsError when logs are dropped.  It can be set to nil if you
+// do not want to provide one.  errHandler must not log to this
+// appender.
+//
+// By default logs are newline framed, reconnection attempts back off
+// exponentially from 100ms to 30s, and dialing and each write time out
+// after 10s.
+func NewBuilder(network, address string, bufferSize int, errHandler func(error)) *networkAppenderBuilder {
+	return &networkAppenderBuilder{
+		network:      network,
+		address:      address,
+		framing:      NewlineFraming,
+		bufferSize:   bufferSize,
+		dialTimeout:  10 * time.Second,
+		writeTimeout: 10 * time.Second,
+		minBackoff:   100 * time.Millisecond,
+		maxBackoff:   30 * time.Second,
+		errHandler:   errHandler,
+		formatter:    nil,
+	}
+}
+
+func (b *networkAppenderBuilder) WithFraming(framing Framing) *networkAppenderBuilder {
+	b.framing = framing
+	return b
+}
+
+// WithBackoff sets the delay before the first reconnection attempt
+// and the maximum delay it doubles up to.
+func (b *networkAppenderBuilder) WithBackoff(minBackoff, maxBackoff time.Duration) *networkAppenderBuilder {
+	b.minBackoff = minBackoff
+	b.maxBackoff = maxBackoff
+	return b
+}
+
+func (b *networkAppenderBuilder) WithDialTimeout(dialTimeout time.Duration) *networkAppenderBuilder {
+	b.dialTimeout = dialTimeout
+	return b
+}
+
+// WithWriteTimeout bounds how long a write to the collector may block,
+// so that a collector that stops reading cannot stall logging.  A
+// write that times out is handled as a failed write.  Set it to 0 for
+// no timeout.
+func (b *networkAppenderBuilder) WithWriteTimeout(writeTimeout time.Duration) *networkAppenderBuilder {
+	b.writeTimeout = writeTimeout
+	return b
+}
+
+// WithFormatter sets the Formatter used to render each log.  Without
+// it the global slogger.GetFormatLogFunc() is used.
+func (b *networkAppenderBuilder) WithFormatter(formatter slogger.Formatter) *networkAppenderBuilder {
+	b.formatter = formatter
+	return b
+}
+
+// Build creates the NetworkAppender and attempts to connect.  If the
+// first connection attempt fails, Build still returns the appender,
+// which buffers logs and keeps trying to connect in the background.
+func (b *networkAppenderBuilder) Build() (*NetworkAppender, error) {
+	if b.framing != NewlineFraming && b.framing != LengthPrefixFraming {
+		return nil, fmt.Errorf("network_appender: invalid framing: %d", b.framing)
+	}
+
+	if b.bufferSize <= 0 {
+		return nil, fmt.Errorf("network_appender: bufferSize must be positive: %d", b.bufferSize)
+	}
+
+	if b.minBackoff <= 0 || b.maxBackoff < b.minBackoff {
+		return nil, fmt.Errorf("network_appender: invalid backoff: %v to %v", b.minBackoff, b.maxBackoff)
+	}
+
+	appender := &NetworkAppender{
+		network:      b.network,
+		address:      b.address,
+		framing:      b.framing,
+		dialTimeout:  b.dialTimeout,
+		writeTimeout: b.writeTimeout,
+		minBackoff:   b.minBackoff,
+		maxBackoff:   b.maxBackoff,
+		errHandler:   b.errHandler,
+		formatter:    b.formatter,
+		closeCh:      make(chan struct{}),
+	}
+	appender.buffer = queue.New(b.bufferSize, func(interface{}) {
+		// called with lock held
+		appender.dropped++
+	})
+
+	conn, err := appender.dial()
+	if err != nil {
+		appender.reportError(err)
+		appender.reconnecting = true
+		go appender.reconnect()
+	} else {
+		appender.conn = conn
+	}
+
+	return appender, nil
+}
+
+// Append sends log to the collector, or buffers it if disconnected.
+// A failed send is reported to the errHandler and the log is buffered
+// until the connection is re-established.
+func (self *NetworkAppender) Append(log *slogger.Log) error {
+	return self.send([][]byte{self.frame(slogger.FormatWith(self.getFormatter(), log))})
+}
+
+// AppendBatch sends logs to the collector with a single write, or
+// buffers them if disconnected, as Append does.
+func (self *NetworkAppender) AppendBatch(logs []*slogger.Log) error {
+	formatter := self.getFormatter()
+	msgs := make([][]byte, len(logs))
+	for i, log := range logs {
+		msgs[i] = self.frame(slogger.FormatWith(formatter, log))
+	}
+	return self.send(msgs)
+}
+
+// send writes msgs to the connection as one write, or buffers them
+// if disconnected or the write fails.  The lock is not held while
+// writing, so a slow collector does not stall other goroutines that
+// are buffering logs.  After a failed write only the messages that
+// were not completely written are buffered.  A message that was partly
+// written is sent again in full, so that it is framed correctly on the
+// new connection.
+func (self *NetworkAppender) send(msgs [][]byte) error {
+	self.lock.Lock()
+	if self.closed {
+		self.lock.Unlock()
+		return ClosedError{}
+	}
+
+	conn := self.conn
+	if conn == nil {
+		self.bufferAll(msgs)
+		dropped := self.takeDropped()
+		self.lock.Unlock()
+
+		if dropped > 0 {
+			self.reportError(DroppedLogsError{dropped})
+		}
+		return nil
+	}
+	self.lock.Unlock()
+
+	written, err := self.write(conn, bytes.Join(msgs, nil))
+	if err == nil {
+		return nil
+	}
+	msgs = unwritten(msgs, written)
+
+	self.lock.Lock()
+	if self.closed {
+		self.lock.Unlock()
+		return ClosedError{}
+	}
+	if self.conn == conn {
+		self.disconnect()
+	}
+	if self.conn != nil {
+		// another goroutine has already reconnected
+		self.lock.Unlock()
+		self.reportError(WriteError{self.address, err})
+		return self.send(msgs)
+	}
+
+	self.bufferAll(msgs)
+	dropped := self.takeDropped()
+	self.lock.Unlock()
+
+	self.reportError(WriteError{self.address, err})
+	if dropped > 0 {
+		self.reportError(DroppedLogsError{dropped})
+	}
+	return nil
+}
+
+// Flush returns a NotConnectedError if logs are buffered waiting for a
+// connection.  Logs are otherwise sent as they are appended.
+func (self *NetworkAppender) Flush() error {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	if buffered := self.buffer.Len(); buffered > 0 {
+		return NotConnectedError{self.address, buffered}
+	}
+	return nil
+}
+
+// Close closes the connection and stops reconnection attempts.  Logs
+// still buffered are discarded.
+func (self *NetworkAppender) Close() error {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	if self.closed {
+		return nil
+	}
+	self.closed = true
+	close(self.closeCh)
+
+	if self.conn == nil {
+		return nil
+	}
+	err := self.conn.Close()
+	self.conn = nil
+	return err
+}
+
+func (self *NetworkAppender) SetFormatter(formatter slogger.Formatter) bool {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	self.formatter = formatter
+	return true
+}
+
+func (self *NetworkAppender) getFormatter() slogger.Formatter {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	return self.formatter
+}
+
+func (self *NetworkAppender) dial() (net.Conn, error) {
+	conn, err := net.DialTimeout(self.network, self.address, self.dialTimeout)
+	if err != nil {
+		return nil, DialError{self.address, err}
+	}
+	return conn, nil
+}
+
+// disconnect closes the connection and starts reconnecting.  It must
+// be called with lock held.
+func (self *NetworkAppender) disconnect() {
+	self.conn.Close()
+	self.conn = nil
+	if !self.reconnecting {
+		self.reconnecting = true
+		go self.reconnect()
+	}
+}
+
+// reconnect dials with exponential backoff until a connection is made
+// and the buffered logs have been sent over it, or until the appender
+// is closed.
+func (self *NetworkAppender) reconnect() {
+	backoff := self.minBackoff
+	for {
+		select {
+		case <-self.closeCh:
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > self.maxBackoff {
+			backoff = self.maxBackoff
+		}
+
+		conn, err := self.dial()
+		if err != nil {
+			self.reportError(err)
+			continue
+		}
+
+		if err := self.sendBuffered(conn); err != nil {
+			conn.Close()
+			self.reportError(err)
+			continue
+		}
+		return
+	}
+}
+
+// sendBuffered writes the buffered messages to conn, joined into one
+// write, until the buffer is empty, and then makes conn the
+// connection.  Logs appended meanwhile are buffered, so that they are
+// sent after the ones buffered before them.  The lock is not held
+// while writing.  Messages that are not completely written are put
+// back at the front of the buffer.
+func (self *NetworkAppender) sendBuffered(conn net.Conn) error {
+	for {
+		self.lock.Lock()
+		if self.closed {
+			self.lock.Unlock()
+			conn.Close()
+			return nil
+		}
+
+		var msgs [][]byte
+		for !self.buffer.IsEmpty() {
+			item, err := self.buffer.Dequeue()
+			if err != nil {
+				break
+			}
+			msgs = append(msgs, item.([]byte))
+		}
+		if len(msgs) == 0 {
+			self.conn = conn
+			self.reconnecting = false
+			self.lock.Unlock()
+			return nil
+		}
+		self.lock.Unlock()
+
+		written, err := self.write(conn, bytes.Join(msgs, nil))
+		if err == nil {
+			continue
+		}
+
+		self.lock.Lock()
+		self.unshift(unwritten(msgs, written))
+		dropped := self.takeDropped()
+		self.lock.Unlock()
+
+		if dropped > 0 {
+			self.reportError(DroppedLogsError{dropped})
+		}
+		return WriteError{self.address, err}
+	}
+}
+
+// bufferAll adds msgs to the buffer, dropping the oldest messages if
+// it is full.  It must be called with lock held.
+func (self *NetworkAppender) bufferAll(msgs [][]byte) {
+	for _, msg := range msgs {
+		self.buffer.Enqueue(msg)
+	}
+}
+
+// unshift puts msgs back at the front of the buffer, ahead of messages
+// buffered since they were taken from it.  It must be called with lock
+// held.
+func (self *NetworkAppender) unshift(msgs [][]byte) {
+	var later []interface{}
+	for !self.buffer.IsEmpty() {
+		item, err := self.buffer.Dequeue()
+		if err != nil {
+			break
+		}
+		later = append(later, item)
+	}
+
+	self.bufferAll(msgs)
+	for _, item := range later {
+		self.buffer.Enqueue(item)
+	}
+}
+
+// unwritten returns the messages in msgs that were not completely
+// written when the first written bytes of them were.
+func unwritten(msgs [][]byte, written int) [][]byte {
+	for len(msgs) > 0 && written >= len(msgs[0]) {
+		written -= len(msgs[0])
+		msgs = msgs[1:]
+	}
+	return msgs
+}
+
+// write writes msg to conn, giving up after writeTimeout.
+func (self *NetworkAppender) write(conn net.Conn, msg []byte) (int, error) {
+	if self.writeTimeout > 0 {
+		if err := conn.SetWriteDeadline(time.Now().Add(self.writeTimeout)); err != nil {
+			return 0, err
+		}
+	}
+	return conn.Write(msg)
+}
+
+// takeDropped returns and resets the count of dropped messages.  It
+// must be called with lock held.
+func (self *NetworkAppender) takeDropped() int {
+	dropped := self.dropped
+	self.dropped = 0
+	return dropped
+}
+
+func (self *NetworkAppender) reportError(err error) {
+	if self.errHandler != nil {
+		self.errHandler(err)
+	}
+}
+
+func (self *NetworkAppender) frame(formatted string) []byte {
+	if self.framing == LengthPrefixFraming {
+		formatted = strings.TrimSuffix(formatted, "\n")
+		msg := make([]byte, 4+len(formatted))
+		binary.BigEndian.PutUint32(msg, uint32(len(formatted)))
+		copy(msg[4:], formatted)
+		return msg
+	}
+
+	if !strings.HasSuffix(formatted, "\n") {
+		formatted += "\n"
+	}
+	return []byte(formatted)
+}
@@ -0,0 +1,425 @@
+// Copyright 2026 MongoDB, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package network_appender
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mongodb/slogger/v2/slogger"
+	. "github.com/mongodb/slogger/v2/slogger/test_util"
+)
+
+func TestNewlineFraming(test *testing.T) {
+	listener := listen(test, "127.0.0.1:0")
+	defer listener.Close()
+	lines := acceptLines(listener)
+
+	appender, logger := newAppenderAndLogger(test, listener.Addr().String(), 10, nil)
+	defer appender.Close()
+
+	_, errs := logger.Logf(slogger.WARN, "This is log message %d", 1)
+	AssertNoErrors(test, errs)
+	_, errs = logger.Logf(slogger.WARN, "This is log message %d", 2)
+	AssertNoErrors(test, errs)
+	AssertNoErrors(test, logger.Flush())
+
+	assertReceived(test, lines, "This is log message 1")
+	assertReceived(test, lines, "This is log message 2")
+}
+
+func TestLengthPrefixFraming(test *testing.T) {
+	listener := listen(test, "127.0.0.1:0")
+	defer listener.Close()
+
+	messages := make(chan string, 10)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			var length uint32
+			if err := binary.Read(conn, binary.BigEndian, &length); err != nil {
+				return
+			}
+			msg := make([]byte, length)
+			if _, err := io.ReadFull(conn, msg); err != nil {
+				return
+			}
+			messages <- string(msg)
+		}
+	}()
+
+	appender, err := NewBuilder("tcp", listener.Addr().String(), 10, nil).
+		WithFraming(LengthPrefixFraming).
+		WithFormatter(slogger.FormatterFunc(slogger.FormatLogJSON)).
+		Build()
+	if err != nil {
+		test.Fatalf("Build() failed: %v", err)
+	}
+	defer appender.Close()
+
+	logger := &slogger.Logger{Appenders: []slogger.Appender{appender}}
+	_, errs := logger.Logf(slogger.INFO, "multi\nline")
+	AssertNoErrors(test, errs)
+
+	msg := assertReceived(test, messages, `"message":"multi\nline"}`)
+	if strings.HasSuffix(msg, "\n") {
+		test.Errorf("Expected no trailing newline in a length prefixed message: %q", msg)
+	}
+}
+
+func TestAppendBatch(test *testing.T) {
+	listener := listen(test, "127.0.0.1:0")
+	defer listener.Close()
+	lines := acceptLines(listener)
+
+	appender, _ := newAppenderAndLogger(test, listener.Addr().String(), 10, nil)
+	defer appender.Close()
+
+	err := appender.AppendBatch([]*slogger.Log{
+		slogger.SimpleLog("na", slogger.WARN, slogger.NoErrorCode, 1, "Batched message 1"),
+		slogger.SimpleLog("na", slogger.WARN, slogger.NoErrorCode, 1, "Batched message 2"),
+	})
+	if err != nil {
+		test.Fatalf("AppendBatch failed: %v", err)
+	}
+
+	assertReceived(test, lines, "Batched message 1")
+	assertReceived(test, lines, "Batched message 2")
+}
+
+func TestBufferAndReconnect(test *testing.T) {
+	// find a free port, then leave it unbound so that the first
+	// connection attempt fails
+	probe := listen(test, "127.0.0.1:0")
+	address := probe.Addr().String()
+	probe.Close()
+
+	errHandler := &recordingErrHandler{}
+	appender, logger := newAppenderAndLogger(test, address, 2, errHandler.handle)
+	defer appender.Close()
+
+	for i := 1; i <= 3; i++ {
+		_, errs := logger.Logf(slogger.WARN, "Buffered message %d", i)
+		AssertNoErrors(test, errs)
+	}
+
+	if err := appender.Flush(); !IsNotConnectedError(err) {
+		test.Errorf("Expected a NotConnectedError while disconnected. Received: %v", err)
+	}
+
+	if !errHandler.received(func(err error) bool { return IsDialError(err) }) {
+		test.Errorf("Expected the failed connection to be reported. Received: %v", errHandler.errors())
+	}
+
+	if !errHandler.received(func(err error) bool {
+		dropped, ok := err.(DroppedLogsError)
+		return ok && dropped.Count == 1
+	}) {
+		test.Errorf("Expected one dropped log to be reported. Received: %v", errHandler.errors())
+	}
+
+	listener := listen(test, address)
+	defer listener.Close()
+	lines := acceptLines(listener)
+
+	// the oldest buffered log was dropped
+	assertReceived(test, lines, "Buffered message 2")
+	assertReceived(test, lines, "Buffered message 3")
+
+	waitUntil(test, func() bool { return appender.Flush() == nil })
+
+	_, errs := logger.Logf(slogger.WARN, "Connected message")
+	AssertNoErrors(test, errs)
+	assertReceived(test, lines, "Connected message")
+}
+
+func TestSlowCollectorDoesNotBlockAppends(test *testing.T) {
+	probe := listen(test, "127.0.0.1:0")
+	address := probe.Addr().String()
+	probe.Close()
+
+	appender, err := NewBuilder("tcp", address, 32, nil).
+		WithBackoff(10*time.Millisecond, 10*time.Millisecond).
+		Build()
+	if err != nil {
+		test.Fatalf("Build() failed: %v", err)
+	}
+	defer appender.Close()
+
+	message := strings.Repeat("x", 1<<20)
+	for i := 0; i < 16; i++ {
+		appender.Append(slogger.SimpleLog("na", slogger.WARN, slogger.NoErrorCode, 1, "%s", message))
+	}
+
+	// accept the reconnection but never read, so that sending the
+	// buffered logs blocks
+	listener := listen(test, address)
+	defer listener.Close()
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			accepted <- conn
+		}
+	}()
+	conn := <-accepted
+	defer conn.Close()
+
+	waitUntil(test, func() bool {
+		appender.lock.Lock()
+		defer appender.lock.Unlock()
+		return appender.buffer.IsEmpty()
+	})
+
+	appended := make(chan struct{})
+	go func() {
+		defer close(appended)
+		appender.Append(slogger.SimpleLog("na", slogger.WARN, slogger.NoErrorCode, 1, "While sending"))
+	}()
+
+	select {
+	case <-appended:
+	case <-time.After(time.Second):
+		test.Fatal("Expected Append not to wait for the buffered logs to be sent")
+	}
+
+	appender.lock.Lock()
+	buffered := appender.buffer.Len()
+	appender.lock.Unlock()
+	if buffered != 1 {
+		test.Errorf("Expected the log to be buffered behind the ones being sent. Buffered: %d", buffered)
+	}
+}
+
+func TestWriteTimeout(test *testing.T) {
+	listener := listen(test, "127.0.0.1:0")
+	defer listener.Close()
+
+	// accept connections but never read from them
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	errHandler := &recordingErrHandler{}
+	appender, err := NewBuilder("tcp", listener.Addr().String(), 10, errHandler.handle).
+		WithBackoff(time.Hour, time.Hour).
+		WithWriteTimeout(50 * time.Millisecond).
+		Build()
+	if err != nil {
+		test.Fatalf("Build() failed: %v", err)
+	}
+	defer appender.Close()
+
+	logger := &slogger.Logger{Appenders: []slogger.Appender{appender}}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		message := strings.Repeat("x", 1<<16)
+		for i := 0; i < 1000 && !errHandler.received(IsWriteError); i++ {
+			logger.Logf(slogger.WARN, "%s", message)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		test.Fatal("Expected logging to continue when the collector stops reading")
+	}
+
+	if !errHandler.received(IsWriteError) {
+		test.Errorf("Expected a WriteError. Received: %v", errHandler.errors())
+	}
+}
+
+func TestPartialWriteBuffersUnwritten(test *testing.T) {
+	listener := listen(test, "127.0.0.1:0")
+	defer listener.Close()
+	acceptLines(listener)
+
+	appender, err := NewBuilder("tcp", listener.Addr().String(), 10, nil).
+		WithBackoff(time.Hour, time.Hour).
+		Build()
+	if err != nil {
+		test.Fatalf("Build() failed: %v", err)
+	}
+	defer appender.Close()
+
+	logs := []*slogger.Log{
+		slogger.SimpleLog("na", slogger.WARN, slogger.NoErrorCode, 1, "Written"),
+		slogger.SimpleLog("na", slogger.WARN, slogger.NoErrorCode, 1, "Partly written"),
+		slogger.SimpleLog("na", slogger.WARN, slogger.NoErrorCode, 1, "Not written"),
+	}
+
+	appender.lock.Lock()
+	written := len(appender.frame(slogger.FormatWith(nil, logs[0]))) + 3
+	appender.conn = &partialConn{Conn: appender.conn, limit: written}
+	appender.lock.Unlock()
+
+	if err := appender.AppendBatch(logs); err != nil {
+		test.Fatalf("AppendBatch failed: %v", err)
+	}
+
+	appender.lock.Lock()
+	defer appender.lock.Unlock()
+
+	var buffered []string
+	for !appender.buffer.IsEmpty() {
+		item, _ := appender.buffer.Dequeue()
+		buffered = append(buffered, string(item.([]byte)))
+	}
+	if len(buffered) != 2 || !strings.Contains(buffered[0], "Partly written") || !strings.Contains(buffered[1], "Not written") {
+		test.Errorf("Expected only the logs not completely written to be buffered. Received: %q", buffered)
+	}
+}
+
+func TestClosed(test *testing.T) {
+	listener := listen(test, "127.0.0.1:0")
+	defer listener.Close()
+
+	appender, logger := newAppenderAndLogger(test, listener.Addr().String(), 10, nil)
+	if err := appender.Close(); err != nil {
+		test.Fatalf("Close() failed: %v", err)
+	}
+
+	_, errs := logger.Logf(slogger.WARN, "Too late")
+	if len(errs) != 1 {
+		test.Errorf("Expected an error appending to a closed appender")
+	}
+}
+
+func newAppenderAndLogger(test *testing.T, address string, bufferSize int, errHandler func(error)) (*NetworkAppender, *slogger.Logger) {
+	appender, err := NewBuilder("tcp", address, bufferSize, errHandler).
+		WithBackoff(10*time.Millisecond, 50*time.Millisecond).
+		Build()
+	if err != nil {
+		test.Fatalf("Build() failed: %v", err)
+	}
+
+	logger := &slogger.Logger{
+		Prefix:    "na",
+		Appenders: []slogger.Appender{appender},
+	}
+	return appender, logger
+}
+
+func listen(test *testing.T, address string) net.Listener {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		test.Fatalf("Failed to listen on %s: %v", address, err)
+	}
+	return listener
+}
+
+// acceptLines sends each line read from connections accepted by
+// listener to the returned channel.
+func acceptLines(listener net.Listener) <-chan string {
+	lines := make(chan string, 100)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					lines <- scanner.Text()
+				}
+			}()
+		}
+	}()
+	return lines
+}
+
+func assertReceived(test *testing.T, messages <-chan string, expected string) string {
+	test.Helper()
+	select {
+	case msg := <-messages:
+		if !strings.Contains(msg, expected) {
+			test.Errorf("Expected %q to contain %q", msg, expected)
+		}
+		return msg
+	case <-time.After(5 * time.Second):
+		test.Fatalf("Timed out waiting for %q", expected)
+		return ""
+	}
+}
+
+func waitUntil(test *testing.T, condition func() bool) {
+	test.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			test.Fatal("Timed out waiting for condition")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+type recordingErrHandler struct {
+	errs []error
+	lock sync.Mutex
+}
+
+func (self *recordingErrHandler) handle(err error) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.errs = append(self.errs, err)
+}
+
+func (self *recordingErrHandler) errors() []error {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	return append([]error{}, self.errs...)
+}
+
+func (self *recordingErrHandler) received(match func(error) bool) bool {
+	for _, err := range self.errors() {
+		if match(err) {
+			return true
+		}
+	}
+	return false
+}
+
+// partialConn fails each write after writing limit bytes of it.
+type partialConn struct {
+	net.Conn
+	limit int
+}
+
+func (self *partialConn) Write(b []byte) (int, error) {
+	if len(b) <= self.limit {
+		return len(b), nil
+	}
+	return self.limit, errors.New("connection reset")
+}
@@ -81,6 +81,21 @@ func (self *RetainingLevelFilterAppender) Flush() error {
 	return self.appender.Flush()
 }
 
+// SetFormatter sets the wrapped Appender's Formatter, if it has one.
+func (self *RetainingLevelFilterAppender) SetFormatter(formatter slogger.Formatter) bool {
+	return slogger.SetAppenderFormatter(self.appender, formatter)
+}
+
+// Enabled reports whether a Log at the given level would be passed
+// through or retained.  While retention is on, every level may be
+// retained, so Enabled only consults Level() when retention is off.
+func (self *RetainingLevelFilterAppender) Enabled(level slogger.Level) bool {
+	if !slogger.AppenderEnabled(self.appender, level) {
+		return false
+	}
+	return self.Retention() || level >= self.Level()
+}
+
 func (self *RetainingLevelFilterAppender) Level() slogger.Level {
 	self.lock.RLock()
 	defer self.lock.RUnlock()
@@ -110,14 +125,9 @@ func (self *RetainingLevelFilterAppender) retainLog(log *slogger.Log) {
 		return
 	}
 
-	categoryInterface, found := log.Context.Get(self.categoryKey)
-	if !found {
-		return
-	}
-
-	category, ok := categoryInterface.(string)
+	category, ok := log.Context.GetString(self.categoryKey)
 	if !ok {
-		// do not retain log if category is not a string
+		// do not retain log if category is missing or not a string
 		return
 	}
 
@@ -93,6 +93,48 @@ func TestRetain(t *testing.T) {
 	assertBufferDoesNotContain(t, buffer, "_MESSAGE_H_")
 }
 
+func TestRetainedContextSnapshot(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	stringAppender := slogger.NewStringAppenderWithFormatter(buffer, slogger.FormatterFunc(slogger.FormatLogfmt))
+	retainingAppender := New("category", 1000, slogger.WARN, stringAppender)
+	logger := &slogger.Logger{Appenders: []slogger.Appender{retainingAppender}}
+
+	context := slogger.NewContext()
+	context.Add("category", "CATEGORY_1")
+	context.Add("attempt", 1)
+
+	_, errs := logger.LogfWithContext(slogger.INFO, "_MESSAGE_A_", context)
+	tu.AssertNoErrors(t, errs)
+
+	// changing the context after logging changes neither the retained
+	// log's fields nor its category
+	context.Add("attempt", 2)
+	context.Add("category", "CATEGORY_2")
+
+	errs = retainingAppender.AppendRetainedLogs("CATEGORY_1")
+	tu.AssertNoErrors(t, errs)
+	assertBufferContains(t, buffer, "msg=_MESSAGE_A_ category=CATEGORY_1 attempt=1\n")
+}
+
+func TestEnabled(t *testing.T) {
+	stringAppender := slogger.NewStringAppender(new(bytes.Buffer))
+	retainingAppender := New("category", 1000, slogger.WARN, stringAppender)
+
+	if !retainingAppender.Enabled(slogger.DEBUG) {
+		t.Errorf("Expected DEBUG to be enabled while retaining")
+	}
+
+	retainingAppender.SetRetention(false)
+	if retainingAppender.Enabled(slogger.INFO) || !retainingAppender.Enabled(slogger.WARN) {
+		t.Errorf("Expected Enabled to respect Level() when not retaining")
+	}
+
+	filtered := New("category", 1000, slogger.DEBUG, slogger.LevelFilter(slogger.ERROR, stringAppender))
+	if filtered.Enabled(slogger.WARN) {
+		t.Errorf("Expected Enabled to respect the wrapped appender")
+	}
+}
+
 func assertBufferContains(t *testing.T, buffer *bytes.Buffer, str string) {
 	bufString := buffer.String()
 	if !strings.Contains(bufString, str) {
@@ -44,6 +44,10 @@ type RollingFileAppender struct {
 
 	lock sync.Mutex
 
+	// formatter may be changed with SetFormatter.  nil means use
+	// slogger.GetFormatLogFunc().
+	formatter slogger.Formatter
+
 	// These fields can change and the lock should be held when
 	// reading or writing to them after construction of the
 	// RollingFileAppender struct
@@ -68,6 +72,7 @@ type rollingFileAppenderBuilder struct {
 	maxUncompressedLogs  int
 	headerGenerator      func() []string
 	stringWriterCallback func(*os.File) slogger.StringWriter
+	formatter            slogger.Formatter
 }
 
 // NewBuilder returns a new rollingFileAppenderBuilder. You can directly
@@ -127,6 +132,7 @@ func NewBuilder(filename string, maxFileSize int64, maxDuration time.Duration, m
 		maxUncompressedLogs:  0,
 		headerGenerator:      headerGenerator,
 		stringWriterCallback: nil,
+		formatter:            nil,
 	}
 }
 
@@ -141,6 +147,14 @@ func (b *rollingFileAppenderBuilder) WithStringWriter(stringWriterCallback func(
 	return b
 }
 
+// WithFormatter sets the Formatter used to render each log line,
+// including header lines.  Without it the global
+// slogger.GetFormatLogFunc() is used.
+func (b *rollingFileAppenderBuilder) WithFormatter(formatter slogger.Formatter) *rollingFileAppenderBuilder {
+	b.formatter = formatter
+	return b
+}
+
 func (b *rollingFileAppenderBuilder) Build() (*RollingFileAppender, error) {
 	if b.headerGenerator == nil {
 		b.headerGenerator = func() []string {
@@ -167,6 +181,7 @@ func (b *rollingFileAppenderBuilder) Build() (*RollingFileAppender, error) {
 		absPath:              absPath,
 		headerGenerator:      b.headerGenerator,
 		stringWriterCallback: b.stringWriterCallback,
+		formatter:            b.formatter,
 	}
 
 	fileInfo, err := os.Stat(absPath)
@@ -231,11 +246,36 @@ func (self *RollingFileAppender) Append(log *slogger.Log) error {
 		return err
 	}
 
-	if (self.maxFileSize > 0 && self.curFileSize > self.maxFileSize) ||
-		(self.maxDuration > 0 &&
-			self.state != nil &&
-			time.Since(self.state.LogStartTime) > self.maxDuration) {
-		return self.rotate()
+	return self.rotateIfNeeded()
+}
+
+// AppendBatch appends logs using as few writes as possible.  The logs
+// are split into more than one write only where Append would rotate
+// the log file between them.
+func (self *RollingFileAppender) AppendBatch(logs []*slogger.Log) error {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	var msgs strings.Builder
+	for i, log := range logs {
+		msgs.WriteString(slogger.FormatWith(self.formatter, log))
+
+		if i < len(logs)-1 &&
+			(self.maxFileSize <= 0 || self.curFileSize+int64(msgs.Len()) <= self.maxFileSize) {
+			continue
+		}
+
+		n, err := self.writeSansSizeTracking(msgs.String())
+		self.curFileSize += int64(n)
+		msgs.Reset()
+
+		if err != nil {
+			return err
+		}
+
+		if err := self.rotateIfNeeded(); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -267,6 +307,14 @@ func (self *RollingFileAppender) Flush() error {
 	return nil
 }
 
+func (self *RollingFileAppender) SetFormatter(formatter slogger.Formatter) bool {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	self.formatter = formatter
+	return true
+}
+
 func (self *RollingFileAppender) Rotate() error {
 	self.lock.Lock()
 	defer self.lock.Unlock()
@@ -342,8 +390,13 @@ func (self *RollingFileAppender) appendSansSizeTracking(log *slogger.Log) (bytes
 	if self.file == nil {
 		return 0, &NoFileError{}
 	}
-	f := slogger.GetFormatLogFunc()
-	msg := f(log)
+	return self.writeSansSizeTracking(slogger.FormatWith(self.formatter, log))
+}
+
+func (self *RollingFileAppender) writeSansSizeTracking(msg string) (bytesWritten int, err error) {
+	if self.file == nil {
+		return 0, &NoFileError{}
+	}
 	bytesWritten, err = self.stringWriterCallback(self.file).WriteString(msg)
 
 	if err != nil {
@@ -516,6 +569,20 @@ func (self *RollingFileAppender) compressLogFile(logpath string) error {
 	return nil
 }
 
+// rotateIfNeeded rotates the log file if it has grown past
+// maxFileSize or is older than maxDuration.  It must be called with
+// lock held.
+func (self *RollingFileAppender) rotateIfNeeded() error {
+	if (self.maxFileSize > 0 && self.curFileSize > self.maxFileSize) ||
+		(self.maxDuration > 0 &&
+			self.state != nil &&
+			time.Since(self.state.LogStartTime) > self.maxDuration) {
+		return self.rotate()
+	}
+
+	return nil
+}
+
 func (self *RollingFileAppender) rotate() error {
 	// close current log if we have one open
 	if self.file != nil {
@@ -122,6 +122,42 @@ func TestRotationSizeBased(test *testing.T) {
 	assertNumLogFiles(test, 2)
 }
 
+func TestAppendBatch(test *testing.T) {
+	defer teardown()
+
+	appender, _ := setup(test, 1000, 0, 10, false)
+	defer appender.Close()
+
+	if err := appender.AppendBatch(batchOfLogs(3)); err != nil {
+		test.Fatalf("AppendBatch failed: %v", err)
+	}
+	assertNumLogFiles(test, 1)
+	assertCurrentLogContains(test, "Batched message 0")
+	assertCurrentLogContains(test, "Batched message 2")
+}
+
+func TestAppendBatchRotation(test *testing.T) {
+	defer teardown()
+
+	appender, _ := setup(test, 10, 0, 10, false)
+	defer appender.Close()
+
+	// each log is more than 10 characters so the batch is split to
+	// rotate after each one
+	if err := appender.AppendBatch(batchOfLogs(3)); err != nil {
+		test.Fatalf("AppendBatch failed: %v", err)
+	}
+	assertNumLogFiles(test, 4)
+}
+
+func batchOfLogs(n int) []*slogger.Log {
+	logs := make([]*slogger.Log, n)
+	for i := range logs {
+		logs[i] = slogger.SimpleLog("rfa", slogger.WARN, slogger.NoErrorCode, 1, "Batched message %d", i)
+	}
+	return logs
+}
+
 func TestRotationTimeBased(test *testing.T) {
 	defer teardown()
 
@@ -269,6 +305,30 @@ func TestCompressionOnRotation(test *testing.T) {
 	}
 }
 
+func TestWithFormatter(test *testing.T) {
+	defer teardown()
+	createLogDir(test)
+
+	appender, err := NewBuilder(rfaTestLogPath, 0, 0, 10, false, nil).
+		WithFormatter(slogger.FormatterFunc(slogger.FormatLogJSON)).
+		Build()
+	if err != nil {
+		test.Fatal("Build() failed: " + err.Error())
+	}
+	defer appender.Close()
+
+	logger := &slogger.Logger{
+		Prefix:    "rfa",
+		Appenders: []slogger.Appender{appender},
+	}
+
+	_, errs := logger.Logf(slogger.WARN, "This is a JSON log message")
+	AssertNoErrors(test, errs)
+	AssertNoErrors(test, logger.Flush())
+
+	assertCurrentLogContains(test, `"message":"This is a JSON log message"`)
+}
+
 func assertCurrentLogContains(test *testing.T, expected string) {
 	assertLogContains(test, rfaTestLogPath, expected)
 }
@@ -0,0 +1,249 @@
+// Copyright 2026 MongoDB, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slogger
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+type samplingBudget struct {
+	first      int // negative means no sampling
+	thereafter int
+}
+
+type samplerKey struct {
+	level      Level
+	messageFmt string
+}
+
+// A Sampler rate limits logs by message format.  In each interval,
+// the first occurrences of a messageFmt at a level are logged, and
+// after that only one in every so many.  Use its Filter method as a
+// TurboFilter so that suppressed logs cost no more than a map lookup.
+type Sampler struct {
+	interval        time.Duration
+	summaryAppender Appender
+	errHandler      func(error)
+	now             func() time.Time
+
+	// where NewSampler was called, which summaries are attributed to
+	filename string
+	funcName string
+	line     int
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+
+	lock sync.Mutex
+
+	// These fields are protected by lock
+	budgets     [topLevel]samplingBudget
+	windowStart time.Time
+	counts      map[samplerKey]int
+	suppressed  [topLevel]int
+}
+
+// NewSampler returns a Sampler that, in each interval, allows the
+// first occurrences of each messageFmt at each level, then every
+// thereafter'th occurrence.  If thereafter is not positive, all
+// occurrences after the first are suppressed.
+//
+// Unless summaryAppender is nil, a WARN Log with the number of logs
+// suppressed at each level is appended to it at the end of every
+// interval in which logs were suppressed.  Summaries are appended from
+// a separate goroutine, which Stop stops, and are attributed to the
+// caller of NewSampler.  errHandler, if not nil, is called with errors
+// returned by summaryAppender.
+//
+// NewSampler panics if interval is not positive, as time.NewTicker
+// does.
+// Example:
+//
+// sampler := slogger.NewSampler(time.Second, 100, 100, appender, nil)
+// defer sampler.Stop()
+// logger.TurboFilters = append(logger.TurboFilters, sampler.Filter)
+func NewSampler(interval time.Duration, first, thereafter int, summaryAppender Appender, errHandler func(error)) *Sampler {
+	if interval <= 0 {
+		panic(fmt.Sprintf("slogger: non-positive interval for NewSampler: %v", interval))
+	}
+
+	sampler := &Sampler{
+		interval:        interval,
+		summaryAppender: summaryAppender,
+		errHandler:      errHandler,
+		now:             time.Now,
+		stopCh:          make(chan struct{}),
+		counts:          make(map[samplerKey]int),
+	}
+	for level := range sampler.budgets {
+		sampler.budgets[level] = samplingBudget{first, thereafter}
+	}
+
+	pc, file, line, ok := runtime.Caller(1)
+	if ok {
+		sampler.filename, sampler.funcName, sampler.line = file, baseFuncNameForPC(pc), line
+	}
+
+	if summaryAppender != nil {
+		go sampler.reportSummaries()
+	}
+	return sampler
+}
+
+// Stop stops appending summaries, after appending one for the logs
+// suppressed in the current interval, if any.  It is safe to call more
+// than once.
+func (self *Sampler) Stop() {
+	self.stopOnce.Do(func() {
+		close(self.stopCh)
+		if self.summaryAppender != nil {
+			self.endWindow(true)
+		}
+	})
+}
+
+// reportSummaries ends the current interval every interval until Stop
+// is called, so that summaries are appended even when nothing more is
+// logged.
+func (self *Sampler) reportSummaries() {
+	ticker := time.NewTicker(self.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			self.endWindow(true)
+		case <-self.stopCh:
+			return
+		}
+	}
+}
+
+// SetLevelBudget sets the budget for logs at level, in place of the
+// one given to NewSampler.  If first is negative, logs at level are
+// never suppressed.
+func (self *Sampler) SetLevelBudget(level Level, first, thereafter int) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	if level < topLevel {
+		self.budgets[level] = samplingBudget{first, thereafter}
+	}
+}
+
+// Filter is a TurboFilter that returns false for logs that should be
+// suppressed.  An empty messageFmt, as passed by Logger.Enabled, is
+// always allowed and not counted.
+func (self *Sampler) Filter(level Level, messageFmt string, args ...interface{}) bool {
+	if messageFmt == "" || level >= topLevel {
+		return true
+	}
+
+	self.endWindow(false)
+
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	return self.allow(level, messageFmt)
+}
+
+// endWindow starts a new interval if the current one has ended, or
+// regardless if force is true, and appends a summary if logs were
+// suppressed in it.
+func (self *Sampler) endWindow(force bool) {
+	self.lock.Lock()
+	summary := self.rollWindow(force)
+	self.lock.Unlock()
+
+	if summary == nil {
+		return
+	}
+	if err := self.summaryAppender.Append(summary); err != nil && self.errHandler != nil {
+		self.errHandler(err)
+	}
+}
+
+// allow counts an occurrence of messageFmt at level and reports whether
+// it should be logged.  It must be called with lock held.
+func (self *Sampler) allow(level Level, messageFmt string) bool {
+	budget := self.budgets[level]
+	if budget.first < 0 {
+		return true
+	}
+
+	key := samplerKey{level, messageFmt}
+	count := self.counts[key] + 1
+	self.counts[key] = count
+
+	if count <= budget.first ||
+		(budget.thereafter > 0 && (count-budget.first)%budget.thereafter == 0) {
+		return true
+	}
+
+	self.suppressed[level]++
+	return false
+}
+
+// rollWindow starts a new interval if the current one has ended, or
+// regardless if force is true, returning a summary Log to append if
+// logs were suppressed in it.  It must be called with lock held.
+func (self *Sampler) rollWindow(force bool) *Log {
+	now := self.now()
+	if !force && now.Sub(self.windowStart) < self.interval {
+		return nil
+	}
+
+	var summary *Log
+	if self.summaryAppender != nil && self.windowStart != (time.Time{}) {
+		summary = self.summaryLog()
+	}
+
+	self.windowStart = now
+	self.counts = make(map[samplerKey]int)
+	self.suppressed = [topLevel]int{}
+	return summary
+}
+
+// summaryLog returns a Log describing the logs suppressed in the
+// current interval, or nil if there were none.  It must be called with
+// lock held.
+func (self *Sampler) summaryLog() *Log {
+	total := 0
+	counts := make([]string, 0, len(self.suppressed))
+	for level, n := range self.suppressed {
+		if n > 0 {
+			total += n
+			counts = append(counts, fmt.Sprintf("%v: %d", Level(level), n))
+		}
+	}
+	if total == 0 {
+		return nil
+	}
+
+	return &Log{
+		Prefix:     "Sampler",
+		Level:      WARN,
+		ErrorCode:  NoErrorCode,
+		Filename:   self.filename,
+		FuncName:   self.funcName,
+		Line:       self.line,
+		Timestamp:  time.Now(),
+		MessageFmt: "Suppressed %d logs in the last %v (%s)",
+		Args:       []interface{}{total, self.interval, strings.Join(counts, ", ")},
+	}
+}
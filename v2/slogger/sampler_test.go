@@ -0,0 +1,193 @@
+// Copyright 2026 MongoDB, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slogger
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSampler(test *testing.T) {
+	summaries := &retainingAppender{}
+	sampler := NewSampler(time.Minute, 2, 3, summaries, nil)
+	defer sampler.Stop()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sampler.now = func() time.Time { return now }
+
+	counter := &countingAppender{}
+	logger := &Logger{
+		Appenders:    []Appender{counter},
+		TurboFilters: []TurboFilter{sampler.Filter},
+	}
+
+	// the first 2, then every 3rd: 1, 2, 5, 8
+	for i := 0; i < 10; i++ {
+		logger.Logf(WARN, "Hot loop %d", i)
+	}
+	if counter.count != 4 {
+		test.Errorf("Expected 4 logs. Received: %d", counter.count)
+	}
+
+	// each messageFmt and level has its own count
+	logger.Logf(WARN, "Elsewhere")
+	logger.Logf(INFO, "Hot loop %d", 0)
+	if counter.count != 6 {
+		test.Errorf("Expected 6 logs. Received: %d", counter.count)
+	}
+
+	if !logger.Enabled(WARN) {
+		test.Errorf("Expected Enabled to be unaffected by sampling")
+	}
+
+	if len(summaries.logs) != 0 {
+		test.Fatalf("Expected no summary before the interval ends")
+	}
+
+	now = now.Add(time.Minute)
+	logger.Logf(WARN, "Hot loop %d", 10)
+	if counter.count != 7 {
+		test.Errorf("Expected counts to reset after the interval. Received: %d logs", counter.count)
+	}
+
+	if len(summaries.logs) != 1 {
+		test.Fatalf("Expected one summary. Received: %d", len(summaries.logs))
+	}
+	if summary := summaries.logs[0]; summary.Level != WARN ||
+		summary.Message() != "Suppressed 6 logs in the last 1m0s (warn: 6)" {
+		test.Errorf("Unexpected summary: %v %q", summary.Level, summary.Message())
+	}
+
+	// no summary when nothing was suppressed
+	now = now.Add(time.Minute)
+	logger.Logf(WARN, "Hot loop %d", 11)
+	if len(summaries.logs) != 1 {
+		test.Errorf("Expected no new summary. Received: %d", len(summaries.logs))
+	}
+}
+
+func TestSamplerLevelBudget(test *testing.T) {
+	sampler := NewSampler(time.Minute, 1, 0, nil, nil)
+	sampler.SetLevelBudget(ERROR, -1, 0)
+
+	allowed := 0
+	for i := 0; i < 5; i++ {
+		if sampler.Filter(DEBUG, "Debug %d", i) {
+			allowed++
+		}
+		if sampler.Filter(ERROR, "Error %d", i) {
+			allowed++
+		}
+	}
+
+	// 1 DEBUG and every ERROR
+	if allowed != 6 {
+		test.Errorf("Expected 6 logs allowed. Received: %d", allowed)
+	}
+}
+
+func TestSamplerErrorf(test *testing.T) {
+	sampler := NewSampler(time.Minute, 1, 0, nil, nil)
+	logger := &Logger{TurboFilters: []TurboFilter{sampler.Filter}}
+
+	for i := 0; i < 3; i++ {
+		err := logger.Errorf(ERROR, "Failed with %d", i)
+		if err == nil || err.Error() != fmt.Sprintf("Failed with %d", i) {
+			test.Errorf("Expected the error to be returned when sampled. Received: %v", err)
+		}
+	}
+}
+
+func TestSamplerPeriodicSummary(test *testing.T) {
+	summaries := &lockedAppender{err: errors.New("disk full")}
+	errs := make(chan error, 10)
+	sampler := NewSampler(20*time.Millisecond, 1, 0, summaries, func(err error) { errs <- err })
+	defer sampler.Stop()
+
+	for i := 0; i < 5; i++ {
+		sampler.Filter(INFO, "Hot loop %d", i)
+	}
+
+	// nothing more is logged, but the summary is still appended
+	select {
+	case err := <-errs:
+		if err != summaries.err {
+			test.Errorf("Expected the summary appender's error. Received: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		test.Fatal("Timed out waiting for a summary")
+	}
+
+	summary := summaries.appended()[0]
+	if summary.Message() != "Suppressed 4 logs in the last 20ms (info: 4)" {
+		test.Errorf("Unexpected summary: %q", summary.Message())
+	}
+	if !strings.HasSuffix(summary.Filename, "sampler_test.go") || summary.FuncName != "TestSamplerPeriodicSummary" {
+		test.Errorf("Expected the summary to be attributed to NewSampler's caller. Received: %s %s", summary.Filename, summary.FuncName)
+	}
+}
+
+func TestSamplerStop(test *testing.T) {
+	summaries := &lockedAppender{}
+	sampler := NewSampler(time.Hour, 0, 0, summaries, nil)
+	sampler.Filter(WARN, "Suppressed")
+
+	sampler.Stop()
+	sampler.Stop()
+	if logs := summaries.appended(); len(logs) != 1 || logs[0].Message() != "Suppressed 1 logs in the last 1h0m0s (warn: 1)" {
+		test.Errorf("Expected a summary when stopped. Received: %v", logs)
+	}
+}
+
+func TestSamplerNonPositiveInterval(test *testing.T) {
+	for _, summaryAppender := range []Appender{nil, &lockedAppender{}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					test.Errorf("Expected NewSampler to panic on a zero interval with summaryAppender %v", summaryAppender)
+				}
+			}()
+			NewSampler(0, 1, 0, summaryAppender, nil)
+		}()
+	}
+}
+
+// lockedAppender retains logs appended from any goroutine and returns
+// err.
+type lockedAppender struct {
+	err  error
+	logs []*Log
+	lock sync.Mutex
+}
+
+func (self *lockedAppender) Append(log *Log) error {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.logs = append(self.logs, log)
+	return self.err
+}
+
+func (self *lockedAppender) Flush() error {
+	return nil
+}
+
+func (self *lockedAppender) appended() []*Log {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	return append([]*Log{}, self.logs...)
+}
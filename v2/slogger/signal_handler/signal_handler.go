@@ -0,0 +1,182 @@
+// Copyright 2026 MongoDB, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package signal_handler reopens and rotates log files and dumps
+// retained logs when the process receives a signal, as logrotate and
+// operators expect of a daemon.
+
+package signal_handler
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+)
+
+type Reopener interface {
+	Reopen() error
+}
+
+type Rotator interface {
+	Rotate() error
+}
+
+type Retainer interface {
+	AppendRetainedLogs(category string) []error
+}
+
+// A SignalHandler dispatches signals to the appenders registered with
+// it.  On Unix platforms:
+//
+//	SIGHUP   reopens every Reopener, such as a RollingFileAppender
+//	         whose file logrotate has renamed
+//	SIGUSR1  rotates every Rotator
+//	SIGUSR2  appends every Retainer's retained logs for its categories
+//
+// Other platforms, such as Windows, have no such signals, so there
+// Start listens for nothing.
+// ReopenAll, RotateAll and DumpRetained can be called directly on
+// every platform.
+type SignalHandler struct {
+	errHandler func(error)
+
+	lock sync.Mutex
+
+	// These fields are protected by lock
+	reopeners  []Reopener
+	rotators   []Rotator
+	retainers  []Retainer
+	categories [][]string // categories[i] are dumped from retainers[i]
+}
+
+// New returns a SignalHandler that calls errHandler with a
+// SignalError for each error returned while handling a signal.
+// errHandler can be nil if you do not want to provide one.
+func New(errHandler func(error)) *SignalHandler {
+	return &SignalHandler{errHandler: errHandler}
+}
+
+func (self *SignalHandler) AddReopener(reopener Reopener) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.reopeners = append(self.reopeners, reopener)
+}
+
+func (self *SignalHandler) AddRotator(rotator Rotator) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.rotators = append(self.rotators, rotator)
+}
+
+// AddRetainer registers retainer to have its logs for each of
+// categories appended by DumpRetained.
+func (self *SignalHandler) AddRetainer(retainer Retainer, categories ...string) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.retainers = append(self.retainers, retainer)
+	self.categories = append(self.categories, categories)
+}
+
+// Start starts listening for signals.  The returned function stops
+// listening and waits for a signal being handled to finish.  It is
+// safe to call more than once.
+func (self *SignalHandler) Start() (stop func()) {
+	actions := self.actions()
+	if len(actions) == 0 {
+		return func() {}
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	for sig := range actions {
+		signal.Notify(sigCh, sig)
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case sig := <-sigCh:
+				for _, err := range actions[sig]() {
+					self.reportError(SignalError{sig, err})
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(sigCh)
+			close(done)
+			<-stopped
+		})
+	}
+}
+
+func (self *SignalHandler) ReopenAll() []error {
+	self.lock.Lock()
+	reopeners := append([]Reopener{}, self.reopeners...)
+	self.lock.Unlock()
+
+	var errs []error
+	for _, reopener := range reopeners {
+		if err := reopener.Reopen(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func (self *SignalHandler) RotateAll() []error {
+	self.lock.Lock()
+	rotators := append([]Rotator{}, self.rotators...)
+	self.lock.Unlock()
+
+	var errs []error
+	for _, rotator := range rotators {
+		if err := rotator.Rotate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func (self *SignalHandler) DumpRetained() []error {
+	self.lock.Lock()
+	retainers := append([]Retainer{}, self.retainers...)
+	categories := append([][]string{}, self.categories...)
+	self.lock.Unlock()
+
+	var errs []error
+	for i, retainer := range retainers {
+		for _, category := range categories[i] {
+			for _, err := range retainer.AppendRetainedLogs(category) {
+				if err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+	}
+	return errs
+}
+
+func (self *SignalHandler) reportError(err error) {
+	if self.errHandler != nil {
+		self.errHandler(err)
+	}
+}
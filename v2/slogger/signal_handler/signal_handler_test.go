@@ -0,0 +1,192 @@
+//go:build unix
+
+// Copyright 2026 MongoDB, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signal_handler
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/mongodb/slogger/v2/slogger/rolling_file_appender"
+)
+
+func TestSIGHUPReopens(test *testing.T) {
+	dir, err := os.MkdirTemp("", "signal_handler")
+	if err != nil {
+		test.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "server.log")
+	appender, err := rolling_file_appender.New(path, 1<<20, 0, 10, false, nil)
+	if err != nil {
+		test.Fatalf("Failed to create appender: %v", err)
+	}
+	defer appender.Close()
+
+	handler := New(nil)
+	handler.AddReopener(appender)
+	stop := handler.Start()
+	defer stop()
+
+	// as logrotate would, rename the file and then signal
+	if err := os.Rename(path, path+".1"); err != nil {
+		test.Fatalf("Failed to rename log file: %v", err)
+	}
+	signalSelf(test, syscall.SIGHUP)
+
+	waitUntil(test, func() bool {
+		_, err := os.Stat(path)
+		return err == nil
+	})
+}
+
+func TestSIGUSR1Rotates(test *testing.T) {
+	rotator := &recorder{}
+	handler := New(nil)
+	handler.AddRotator(rotator)
+	stop := handler.Start()
+	defer stop()
+
+	signalSelf(test, syscall.SIGUSR1)
+	waitUntil(test, func() bool { return rotator.count() == 1 })
+}
+
+func TestSIGUSR2DumpsRetained(test *testing.T) {
+	retainer := &recorder{}
+	handler := New(nil)
+	handler.AddRetainer(retainer, "query", "replication")
+	stop := handler.Start()
+	defer stop()
+
+	signalSelf(test, syscall.SIGUSR2)
+	waitUntil(test, func() bool { return retainer.count() == 2 })
+
+	categories := retainer.categoriesDumped()
+	if categories[0] != "query" || categories[1] != "replication" {
+		test.Errorf("Expected query and replication to be dumped. Received: %v", categories)
+	}
+}
+
+func TestErrorsAreReported(test *testing.T) {
+	errCh := make(chan error, 1)
+	rotator := &recorder{err: errors.New("disk full")}
+	handler := New(func(err error) { errCh <- err })
+	handler.AddRotator(rotator)
+	stop := handler.Start()
+	defer stop()
+
+	signalSelf(test, syscall.SIGUSR1)
+
+	select {
+	case err := <-errCh:
+		signalErr, ok := err.(SignalError)
+		if !ok || signalErr.Signal != syscall.SIGUSR1 || signalErr.Err != rotator.err {
+			test.Errorf("Expected a SignalError for SIGUSR1. Received: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		test.Fatal("Timed out waiting for an error")
+	}
+}
+
+func TestStop(test *testing.T) {
+	rotator := &recorder{}
+	handler := New(nil)
+	handler.AddRotator(rotator)
+	stop := handler.Start()
+
+	signalSelf(test, syscall.SIGUSR1)
+	waitUntil(test, func() bool { return rotator.count() == 1 })
+
+	stop()
+	stop()
+
+	// with no one listening SIGUSR1 would end the test binary, so
+	// check that the SignalHandler no longer handles it by listening
+	// separately
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGUSR1)
+	defer signal.Stop(sigCh)
+
+	signalSelf(test, syscall.SIGUSR1)
+	select {
+	case <-sigCh:
+	case <-time.After(5 * time.Second):
+		test.Fatal("Timed out waiting for SIGUSR1")
+	}
+
+	if rotator.count() != 1 {
+		test.Errorf("Expected no rotation after stopping. Received: %d", rotator.count())
+	}
+}
+
+func signalSelf(test *testing.T, sig syscall.Signal) {
+	if err := syscall.Kill(os.Getpid(), sig); err != nil {
+		test.Fatalf("Failed to send %v: %v", sig, err)
+	}
+}
+
+func waitUntil(test *testing.T, condition func() bool) {
+	test.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			test.Fatal("Timed out waiting for condition")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// recorder is both a Rotator and a Retainer
+type recorder struct {
+	err        error
+	calls      int
+	categories []string
+	lock       sync.Mutex
+}
+
+func (self *recorder) Rotate() error {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.calls++
+	return self.err
+}
+
+func (self *recorder) AppendRetainedLogs(category string) []error {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.calls++
+	self.categories = append(self.categories, category)
+	return []error{self.err}
+}
+
+func (self *recorder) count() int {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	return self.calls
+}
+
+func (self *recorder) categoriesDumped() []string {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	return append([]string{}, self.categories...)
+}
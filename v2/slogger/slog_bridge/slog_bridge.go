@@ -0,0 +1,251 @@
+// Copyright 2026 MongoDB, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package slog_bridge connects slogger to the standard library's
+// log/slog package in both directions.  Handler lets code written
+// against log/slog log through slogger Appenders, and HandlerAppender
+// lets a slogger Logger log to any slog.Handler.
+
+package slog_bridge
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"runtime"
+	"strings"
+
+	"github.com/mongodb/slogger/v2/slogger"
+)
+
+// Handler is a slog.Handler that converts each slog.Record into a
+// *slogger.Log and appends it to a slogger Logger's Appenders.
+//
+// The Logger's Prefix, StripDirs and Context are applied to every
+// Log, and its TurboFilters are consulted with the record's message.
+// Fields attached to the context.Context passed to Handle with
+// slogger.AttachContext are added after the Logger's fields.
+// Attributes become Context fields.  Attributes inside groups are
+// keyed by the group names and attribute key joined with periods
+// (e.g. "request.id").
+type Handler struct {
+	logger *slogger.Logger
+	groups []string // open groups, applied to attributes added later
+}
+
+// NewHandler returns a Handler that logs through logger's Appenders.
+// Example:
+//
+// logger := &slogger.Logger{Prefix: "server", Appenders: appenders}
+// slog.SetDefault(slog.New(slog_bridge.NewHandler(logger)))
+func NewHandler(logger *slogger.Logger) *Handler {
+	return &Handler{logger: logger}
+}
+
+func (self *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return self.logger.Enabled(FromSlogLevel(level))
+}
+
+func (self *Handler) Handle(ctx context.Context, record slog.Record) error {
+	fields := self.logger.Context.Merge(slogger.ContextFrom(ctx))
+	if fields == nil {
+		fields = slogger.NewContext()
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		addAttr(fields, self.groups, attr)
+		return true
+	})
+
+	log := &slogger.Log{
+		Prefix:     self.logger.Prefix,
+		Level:      FromSlogLevel(record.Level),
+		ErrorCode:  slogger.NoErrorCode,
+		Timestamp:  record.Time,
+		MessageFmt: strings.ReplaceAll(record.Message, "%", "%%"),
+		Context:    fields,
+	}
+	log.TraceID, log.SpanID = slogger.ExtractTrace(ctx)
+
+	if record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		log.Filename = slogger.StripDirectories(frame.File, self.logger.StripDirs)
+		log.FuncName = frame.Function[strings.LastIndex(frame.Function, ".")+1:]
+		log.Line = frame.Line
+	}
+
+	return errors.Join(self.logger.AppendLog(log)...)
+}
+
+func (self *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return self
+	}
+
+	fields := slogger.NewContext()
+	for _, attr := range attrs {
+		addAttr(fields, self.groups, attr)
+	}
+
+	return &Handler{
+		logger: self.logger.Child("", fields),
+		groups: self.groups,
+	}
+}
+
+func (self *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return self
+	}
+
+	groups := make([]string, len(self.groups), len(self.groups)+1)
+	copy(groups, self.groups)
+	return &Handler{
+		logger: self.logger,
+		groups: append(groups, name),
+	}
+}
+
+// addAttr adds attr to fields, flattening groups into period
+// separated keys.  Empty attributes and empty groups are dropped, and
+// groups with an empty key are inlined, as slog.Handler requires.
+func addAttr(fields *slogger.Context, groups []string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			groups = append(groups[:len(groups):len(groups)], attr.Key)
+		}
+		for _, groupAttr := range attr.Value.Group() {
+			addAttr(fields, groups, groupAttr)
+		}
+		return
+	}
+
+	key := attr.Key
+	if len(groups) > 0 {
+		key = strings.Join(groups, ".") + "." + key
+	}
+	fields.Add(key, attr.Value.Any())
+}
+
+// HandlerAppender is a slogger Appender that converts each Log into a
+// slog.Record and passes it to a slog.Handler.  The Log's prefix and
+// error code are added as the "prefix" and "errorCode" attributes, its
+// location as a slog.Source under slog.SourceKey, and its Context
+// fields as further attributes.
+//
+// The slog.Handler does its own formatting, so the text of a
+// preformatted Log (see slogger.Log.Preformat) is not used.
+type HandlerAppender struct {
+	handler slog.Handler
+}
+
+func NewHandlerAppender(handler slog.Handler) *HandlerAppender {
+	return &HandlerAppender{handler}
+}
+
+func (self *HandlerAppender) Append(log *slogger.Log) error {
+	ctx := context.Background()
+	level := ToSlogLevel(log.Level)
+	if !self.handler.Enabled(ctx, level) {
+		return nil
+	}
+
+	record := slog.NewRecord(log.Timestamp, level, log.Message(), 0)
+	if log.Prefix != "" {
+		record.AddAttrs(slog.String("prefix", log.Prefix))
+	}
+	if log.ErrorCode != slogger.NoErrorCode {
+		record.AddAttrs(slog.Int("errorCode", int(log.ErrorCode)))
+	}
+	if log.TraceID != "" {
+		record.AddAttrs(slog.String("traceId", log.TraceID))
+	}
+	if log.SpanID != "" {
+		record.AddAttrs(slog.String("spanId", log.SpanID))
+	}
+	if log.Filename != "" {
+		record.AddAttrs(slog.Any(slog.SourceKey, &slog.Source{
+			Function: log.FuncName,
+			File:     log.Filename,
+			Line:     log.Line,
+		}))
+	}
+	log.Context.Range(func(key string, value interface{}) bool {
+		record.AddAttrs(slog.Any(key, value))
+		return true
+	})
+
+	return self.handler.Handle(ctx, record)
+}
+
+func (self *HandlerAppender) Flush() error {
+	return nil
+}
+
+// Enabled defers to the slog.Handler.
+func (self *HandlerAppender) Enabled(level slogger.Level) bool {
+	return self.handler.Enabled(context.Background(), ToSlogLevel(level))
+}
+
+// FromSlogLevel maps a slog.Level to the slogger.Level whose range
+// contains it.  Levels below slog.LevelDebug are TRACE and levels
+// above slog.LevelError are FATAL.
+func FromSlogLevel(level slog.Level) slogger.Level {
+	switch {
+	case level < slog.LevelDebug:
+		return slogger.TRACE
+	case level < slog.LevelInfo:
+		return slogger.DEBUG
+	case level < slog.LevelWarn:
+		return slogger.INFO
+	case level < slog.LevelError:
+		return slogger.WARN
+	case level < slogLevelFatal:
+		return slogger.ERROR
+	default:
+		return slogger.FATAL
+	}
+}
+
+// ToSlogLevel maps a slogger.Level to a slog.Level.  TRACE, FATAL and
+// OFF, which slog does not name, are placed 4 below slog.LevelDebug,
+// 4 above slog.LevelError and 8 above slog.LevelError respectively.
+func ToSlogLevel(level slogger.Level) slog.Level {
+	switch level {
+	case slogger.TRACE:
+		return slogLevelTrace
+	case slogger.DEBUG:
+		return slog.LevelDebug
+	case slogger.INFO:
+		return slog.LevelInfo
+	case slogger.WARN:
+		return slog.LevelWarn
+	case slogger.ERROR:
+		return slog.LevelError
+	case slogger.FATAL:
+		return slogLevelFatal
+	default:
+		return slogLevelOff
+	}
+}
+
+const (
+	slogLevelTrace = slog.LevelDebug - 4
+	slogLevelFatal = slog.LevelError + 4
+	slogLevelOff   = slog.LevelError + 8
+)
@@ -0,0 +1,170 @@
+// Copyright 2026 MongoDB, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slog_bridge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mongodb/slogger/v2/slogger"
+	. "github.com/mongodb/slogger/v2/slogger/test_util"
+)
+
+type retainingAppender struct {
+	logs []*slogger.Log
+}
+
+func (self *retainingAppender) Append(log *slogger.Log) error {
+	self.logs = append(self.logs, log)
+	return nil
+}
+
+func (self *retainingAppender) Flush() error {
+	return nil
+}
+
+func TestHandler(test *testing.T) {
+	appender := &retainingAppender{}
+	logger := &slogger.Logger{
+		Prefix:    "server",
+		Appenders: []slogger.Appender{slogger.LevelFilter(slogger.INFO, appender)},
+		Context:   slogger.NewContext(),
+	}
+	logger.Context.Add("host", "db1")
+
+	slogLogger := slog.New(NewHandler(logger)).With("conn", 12).WithGroup("req")
+	slogLogger.Debug("filtered")
+	slogLogger.Warn("100% slow", "id", 7, slog.Group("user", "name", "alice"), slog.Group("empty"))
+
+	if len(appender.logs) != 1 {
+		test.Fatalf("Expected exactly one log. Received: %d", len(appender.logs))
+	}
+
+	log := appender.logs[0]
+	if log.Level != slogger.WARN || log.Prefix != "server" || log.Message() != "100% slow" {
+		test.Errorf("Unexpected log: %v %v %v", log.Level, log.Prefix, log.Message())
+	}
+
+	if log.Filename != "slog_bridge_test.go" || log.FuncName != "TestHandler" || log.Line == 0 {
+		test.Errorf("Expected source to be the test. Received: %v:%v:%v", log.Filename, log.FuncName, log.Line)
+	}
+
+	expectedKeys := []string{"host", "conn", "req.id", "req.user.name"}
+	if !reflect.DeepEqual(log.Context.Keys(), expectedKeys) {
+		test.Fatalf("Expected keys %v. Received: %v", expectedKeys, log.Context.Keys())
+	}
+
+	if value, _ := log.Context.Get("req.user.name"); value != "alice" {
+		test.Errorf("Expected req.user.name to be alice. Received: %v", value)
+	}
+
+	if value, _ := log.Context.Get("req.id"); value != int64(7) {
+		test.Errorf("Expected req.id to be 7. Received: %#v", value)
+	}
+}
+
+func TestHandlerLevels(test *testing.T) {
+	appender := &retainingAppender{}
+	logger := &slogger.Logger{
+		Prefix:    "server.repl",
+		Appenders: []slogger.Appender{appender},
+		Levels:    slogger.NewLevelRegistry(slogger.INFO),
+	}
+	logger.Levels.SetLevel("server.repl", slogger.WARN)
+
+	// Handle is called without Enabled, as by handlers that wrap this one
+	handler := NewHandler(logger)
+	handler.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "filtered", 0))
+	handler.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelWarn, "logged", 0))
+
+	if len(appender.logs) != 1 || appender.logs[0].Message() != "logged" {
+		test.Errorf("Expected Handle to respect the Logger's Levels. Received: %v", appender.logs)
+	}
+}
+
+func TestHandlerContextFields(test *testing.T) {
+	appender := &retainingAppender{}
+	logger := &slogger.Logger{Appenders: []slogger.Appender{appender}}
+
+	ctx := slogger.AttachFields(context.Background(), "requestId", 42)
+	slog.New(NewHandler(logger)).InfoContext(ctx, "Handled", "status", 200)
+
+	if len(appender.logs) != 1 {
+		test.Fatalf("Expected exactly one log. Received: %d", len(appender.logs))
+	}
+
+	expectedKeys := []string{"requestId", "status"}
+	if keys := appender.logs[0].Context.Keys(); !reflect.DeepEqual(keys, expectedKeys) {
+		test.Errorf("Expected keys %v. Received: %v", expectedKeys, keys)
+	}
+}
+
+func TestHandlerAppender(test *testing.T) {
+	buffer := new(bytes.Buffer)
+	handler := slog.NewJSONHandler(buffer, &slog.HandlerOptions{Level: slog.LevelInfo})
+	logger := &slogger.Logger{
+		Prefix:    "server",
+		Appenders: []slogger.Appender{NewHandlerAppender(handler)},
+	}
+
+	if logger.Enabled(slogger.DEBUG) {
+		test.Errorf("Expected Enabled to defer to the handler")
+	}
+
+	ctxt := slogger.NewContext()
+	ctxt.Add("shard", 3)
+	_, errs := logger.LogfWithErrorCodeAndContext(slogger.ERROR, 9, "Lost %s", ctxt, "primary")
+	AssertNoErrors(test, errs)
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(buffer.Bytes(), &decoded); err != nil {
+		test.Fatalf("Could not decode `%v`: %v", buffer.String(), err)
+	}
+
+	expected := map[string]interface{}{
+		"level":     "ERROR",
+		"msg":       "Lost primary",
+		"prefix":    "server",
+		"errorCode": float64(9),
+		"shard":     float64(3),
+	}
+	for key, value := range expected {
+		if decoded[key] != value {
+			test.Errorf("Expected %v to be %#v. Received: %#v", key, value, decoded[key])
+		}
+	}
+
+	source, _ := decoded["source"].(map[string]interface{})
+	if source["function"] != "TestHandlerAppender" || source["file"] != "slog_bridge_test.go" {
+		test.Errorf("Expected source to be the test. Received: %v", decoded["source"])
+	}
+}
+
+func TestLevelMapping(test *testing.T) {
+	for level := slogger.TRACE; level <= slogger.FATAL; level++ {
+		if roundTripped := FromSlogLevel(ToSlogLevel(level)); roundTripped != level {
+			test.Errorf("Expected %v to round trip. Received: %v", level, roundTripped)
+		}
+	}
+
+	if FromSlogLevel(slog.LevelInfo+2) != slogger.INFO || FromSlogLevel(slog.LevelDebug-1) != slogger.TRACE {
+		test.Errorf("Expected intermediate slog levels to map to the enclosing range")
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright 2026 MongoDB, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slogger
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInfow(test *testing.T) {
+	buffer := new(bytes.Buffer)
+	logger := &Logger{
+		Prefix:    "agent",
+		Appenders: []Appender{NewStringAppender(buffer)},
+	}
+
+	log, errs := logger.Infow("100% done", "user", "alice", "shard", 3)
+	if len(errs) != 0 {
+		test.Fatalf("Expected no errors: %v", errs)
+	}
+
+	if log.Level != INFO {
+		test.Errorf("Expected INFO. Received: %v", log.Level)
+	}
+
+	if log.Message() != "100% done" {
+		test.Errorf("Expected message to be used verbatim. Received: %v", log.Message())
+	}
+
+	assertContextFields(test, log.Context, []string{"user", "shard"}, []interface{}{"alice", 3})
+
+	if !strings.Contains(buffer.String(), "[structured_logger_test.go:TestInfow:") {
+		test.Errorf("Expected caller to be the test. Received: %v", buffer.String())
+	}
+}
+
+func TestLogwBadKeys(test *testing.T) {
+	logger := &Logger{}
+
+	log, _ := logger.Logw(WARN, "msg", 1, "a", 2, "dangling")
+	assertContextFields(test, log.Context, []string{badKey, "a"}, []interface{}{"dangling", 2})
+}
+
+func TestWith(test *testing.T) {
+	counter := &countingAppender{}
+	logger := &Logger{
+		Prefix:    "agent",
+		Appenders: []Appender{counter},
+	}
+
+	connLogger := logger.With("remote", "10.0.0.1", "shard", 1)
+	shardLogger := connLogger.With("shard", 2, "db", "test")
+
+	if logger.Context != nil {
+		test.Errorf("Expected parent logger to be unchanged. Received: %v", logger.Context.Keys())
+	}
+
+	log, _ := shardLogger.Warnw("Slow query", "millis", 150, "remote", "10.0.0.2")
+	assertContextFields(test, log.Context,
+		[]string{"remote", "shard", "db", "millis"},
+		[]interface{}{"10.0.0.2", 2, "test", 150})
+
+	log, _ = connLogger.Logf(INFO, "Plain")
+	assertContextFields(test, log.Context, []string{"remote", "shard"}, []interface{}{"10.0.0.1", 1})
+
+	if counter.count != 2 {
+		test.Errorf("Expected child loggers to share appenders. Received count: %d", counter.count)
+	}
+}
+
+func assertContextFields(test *testing.T, ctxt *Context, keys []string, values []interface{}) {
+	test.Helper()
+
+	if ctxt == nil {
+		test.Fatalf("Expected a context with keys %v", keys)
+	}
+
+	if !reflect.DeepEqual(ctxt.Keys(), keys) {
+		test.Fatalf("Expected keys %v. Received: %v", keys, ctxt.Keys())
+	}
+
+	for i, key := range keys {
+		value, _ := ctxt.Get(key)
+		if value != values[i] {
+			test.Errorf("Expected %v to be %v. Received: %v", key, values[i], value)
+		}
+	}
+}
+
+func TestLogfCtx(test *testing.T) {
+	buffer := new(bytes.Buffer)
+	logger := &Logger{
+		Appenders: []Appender{NewStringAppender(buffer)},
+		Context:   NewContextFromPairs("tenant", "default", "host", "db1"),
+	}
+
+	ctx := AttachFields(context.Background(), "requestId", 42, "tenant", "acme")
+	ctx = AttachFields(ctx, "user", "alice")
+
+	log, errs := logger.LogfCtx(ctx, WARN, "Request took %dms", 150)
+	if len(errs) != 0 {
+		test.Fatalf("Expected no errors: %v", errs)
+	}
+
+	assertContextFields(test, log.Context,
+		[]string{"tenant", "host", "requestId", "user"},
+		[]interface{}{"acme", "db1", 42, "alice"})
+
+	if !strings.Contains(buffer.String(), "[structured_logger_test.go:TestLogfCtx:") {
+		test.Errorf("Expected caller to be the test. Received: %v", buffer.String())
+	}
+
+	log, _ = logger.LogfCtx(context.Background(), INFO, "No request")
+	assertContextFields(test, log.Context, []string{"tenant", "host"}, []interface{}{"default", "db1"})
+}
+
+func TestLogwCtx(test *testing.T) {
+	logger := &Logger{Context: NewContextFromPairs("a", 1)}
+	ctx := AttachContext(context.Background(), NewContextFromPairs("b", 2, "c", 3))
+
+	log, _ := logger.LogwCtx(ctx, INFO, "Merged", "c", 4, "d", 5)
+	assertContextFields(test, log.Context,
+		[]string{"a", "b", "c", "d"},
+		[]interface{}{1, 2, 4, 5})
+
+	// the fields attached to ctx are unchanged
+	assertContextFields(test, ContextFrom(ctx), []string{"b", "c"}, []interface{}{2, 3})
+}
+
+type traceKey struct{}
+
+func TestTraceExtractor(test *testing.T) {
+	SetTraceExtractor(func(ctx context.Context) (string, string) {
+		ids, _ := ctx.Value(traceKey{}).([2]string)
+		return ids[0], ids[1]
+	})
+	defer SetTraceExtractor(nil)
+
+	logger := &Logger{}
+	ctx := context.WithValue(context.Background(), traceKey{}, [2]string{"4bf92f35", "00f067aa"})
+
+	log, _ := logger.LogfCtx(ctx, INFO, "Traced")
+	if log.TraceID != "4bf92f35" || log.SpanID != "00f067aa" {
+		test.Fatalf("Expected trace and span IDs. Received: %q %q", log.TraceID, log.SpanID)
+	}
+
+	for formatted, expected := range map[string]string{
+		FormatLog(log):     "[trace:4bf92f35 span:00f067aa] Traced",
+		FormatLogJSON(log): `"traceId":"4bf92f35","spanId":"00f067aa"`,
+		FormatLogfmt(log):  "traceId=4bf92f35 spanId=00f067aa",
+	} {
+		if !strings.Contains(formatted, expected) {
+			test.Errorf("Expected %q in %q", expected, formatted)
+		}
+	}
+
+	// Logs made without a context.Context carry no trace
+	log, _ = logger.Logf(INFO, "Untraced")
+	if log.TraceID != "" || strings.Contains(FormatLog(log), "trace:") || strings.Contains(FormatLogJSON(log), "traceId") {
+		test.Errorf("Expected no trace. Received: %q %q", log.TraceID, FormatLog(log))
+	}
+}
+
+func TestChild(test *testing.T) {
+	buffer := new(bytes.Buffer)
+	logger := &Logger{
+		Prefix:       "server",
+		Appenders:    []Appender{NewStringAppender(buffer)},
+		TurboFilters: []TurboFilter{TurboLevelFilter(INFO)},
+		Context:      NewContext(),
+	}
+	logger.Context.Add("host", "db1")
+
+	replCtx := NewContext()
+	replCtx.Add("component", "repl")
+	replLogger := logger.Child("repl", replCtx)
+	oplogLogger := replLogger.Child("oplog", nil)
+
+	if oplogLogger.Prefix != "server.repl.oplog" {
+		test.Errorf("Expected composed prefix. Received: %v", oplogLogger.Prefix)
+	}
+
+	log, _ := oplogLogger.Logf(INFO, "Applied %d ops", 10)
+	assertContextFields(test, log.Context, []string{"host", "component"}, []interface{}{"db1", "repl"})
+
+	if !strings.Contains(buffer.String(), "[server.repl.oplog.info]") {
+		test.Errorf("Expected child to use parent's appenders. Received: %v", buffer.String())
+	}
+
+	if log, _ := oplogLogger.Logf(DEBUG, "Filtered"); log != nil {
+		test.Errorf("Expected child to use parent's turbo filters")
+	}
+
+	replLogger.Appenders = append(replLogger.Appenders, &countingAppender{})
+	replLogger.TurboFilters = append(replLogger.TurboFilters, TurboLevelFilter(WARN))
+	if len(logger.Appenders) != 1 || len(logger.TurboFilters) != 1 || len(oplogLogger.TurboFilters) != 1 {
+		test.Errorf("Expected appending to a child's slices to leave other loggers unchanged")
+	}
+
+	if (&Logger{}).Child("repl", nil).Prefix != "repl" {
+		test.Errorf("Expected no leading period when the parent has no prefix")
+	}
+}
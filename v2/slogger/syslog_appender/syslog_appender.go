@@ -0,0 +1,510 @@
+// Copyright 2026 MongoDB, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package syslog_appender provides a slogger Appender that sends logs
+// to a syslog daemon in RFC 5424 or RFC 3164 format.
+
+package syslog_appender
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mongodb/slogger/v2/slogger"
+)
+
+type Format int
+
+const (
+	// RFC5424 messages carry the Log's Context as structured data.
+	RFC5424 Format = iota
+	// RFC3164 is the older BSD syslog format.
+	RFC3164
+)
+
+type Facility int
+
+const (
+	KERN Facility = iota
+	USER
+	MAIL
+	DAEMON
+	AUTH
+	SYSLOG
+	LPR
+	NEWS
+	UUCP
+	CRON
+	AUTHPRIV
+	FTP
+	_ // 12 through 15 are reserved for system use
+	_
+	_
+	_
+	LOCAL0
+	LOCAL1
+	LOCAL2
+	LOCAL3
+	LOCAL4
+	LOCAL5
+	LOCAL6
+	LOCAL7
+)
+
+type Severity int
+
+const (
+	EMERG Severity = iota
+	ALERT
+	CRIT
+	ERR
+	WARNING
+	NOTICE
+	INFO
+	DEBUG
+)
+
+// SeverityForLevel maps a slogger.Level to a syslog severity.  TRACE
+// and DEBUG are both DEBUG, and FATAL is CRIT.
+func SeverityForLevel(level slogger.Level) Severity {
+	switch level {
+	case slogger.TRACE, slogger.DEBUG:
+		return DEBUG
+	case slogger.INFO:
+		return INFO
+	case slogger.WARN:
+		return WARNING
+	case slogger.ERROR:
+		return ERR
+	default:
+		return CRIT
+	}
+}
+
+// sdID identifies the structured data element holding a Log's
+// Context.  32473 is the private enterprise number reserved for
+// documentation by RFC 5612.
+const sdID = "slogger@32473"
+
+// local syslog sockets tried, in order, when no network is given
+var localSyslogPaths = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+type SyslogAppender struct {
+	// These fields should not need to change
+	network      string
+	address      string
+	format       Format
+	facility     Facility
+	appName      string
+	hostname     string
+	pid          string
+	dialTimeout  time.Duration
+	writeTimeout time.Duration
+	minBackoff   time.Duration
+	maxBackoff   time.Duration
+	formatter    slogger.Formatter
+
+	lock sync.Mutex
+
+	// These fields are protected by lock.  conn is nil while
+	// disconnected, and no connection is attempted before nextDial.
+	conn     net.Conn
+	backoff  time.Duration
+	nextDial time.Time
+}
+
+type syslogAppenderBuilder struct {
+	network      string
+	address      string
+	format       Format
+	facility     Facility
+	appName      string
+	hostname     string
+	dialTimeout  time.Duration
+	writeTimeout time.Duration
+	minBackoff   time.Duration
+	maxBackoff   time.Duration
+	formatter    slogger.Formatter
+}
+
+// NewBuilder returns a new syslogAppenderBuilder.  You can directly
+// call Build() to create a new SyslogAppender, or configure
+// additional options first.
+//
+// network and address are as for net.Dial.  Messages sent over a
+// stream ("tcp", "tcp4", "tcp6" or "unix") are framed by octet
+// counting as described in RFC 6587.  Each message sent over a
+// datagram network ("udp", "udp4", "udp6" or "unixgram") is a single
+// datagram.  If network is empty, the local syslog daemon's datagram
+// socket is used.
+//
+// By default messages are RFC 5424 formatted with the USER facility,
+// the local host name, and an app-name taken from each Log's prefix.
+// Dialing and each write time out after 10s, and after a failed
+// connection attempt no other is made for a delay that backs off
+// exponentially from 100ms to 30s.
+func NewBuilder(network, address string) *syslogAppenderBuilder {
+	return &syslogAppenderBuilder{
+		network:      network,
+		address:      address,
+		format:       RFC5424,
+		facility:     USER,
+		appName:      "",
+		hostname:     "",
+		dialTimeout:  10 * time.Second,
+		writeTimeout: 10 * time.Second,
+		minBackoff:   100 * time.Millisecond,
+		maxBackoff:   30 * time.Second,
+		formatter:    nil,
+	}
+}
+
+func (b *syslogAppenderBuilder) WithFormat(format Format) *syslogAppenderBuilder {
+	b.format = format
+	return b
+}
+
+func (b *syslogAppenderBuilder) WithFacility(facility Facility) *syslogAppenderBuilder {
+	b.facility = facility
+	return b
+}
+
+// WithAppName sets a fixed app-name (the TAG in RFC 3164).  Without
+// it each Log's prefix is used.
+func (b *syslogAppenderBuilder) WithAppName(appName string) *syslogAppenderBuilder {
+	b.appName = appName
+	return b
+}
+
+func (b *syslogAppenderBuilder) WithHostname(hostname string) *syslogAppenderBuilder {
+	b.hostname = hostname
+	return b
+}
+
+func (b *syslogAppenderBuilder) WithDialTimeout(dialTimeout time.Duration) *syslogAppenderBuilder {
+	b.dialTimeout = dialTimeout
+	return b
+}
+
+// WithWriteTimeout bounds how long sending a message may block.  Set
+// it to 0 for no timeout.
+func (b *syslogAppenderBuilder) WithWriteTimeout(writeTimeout time.Duration) *syslogAppenderBuilder {
+	b.writeTimeout = writeTimeout
+	return b
+}
+
+// WithBackoff sets how long Append waits after a failed connection
+// attempt before trying again, and the maximum delay it doubles up to.
+// Logs appended in the meantime are not sent and Append returns an
+// error.
+func (b *syslogAppenderBuilder) WithBackoff(minBackoff, maxBackoff time.Duration) *syslogAppenderBuilder {
+	b.minBackoff = minBackoff
+	b.maxBackoff = maxBackoff
+	return b
+}
+
+// WithFormatter sets the Formatter used to render the MSG part of
+// each syslog message.  Without it only the Log's message is sent, as
+// the syslog header already carries its time, level and app-name.  A
+// preformatted Log is sent as preformatted in either case.
+func (b *syslogAppenderBuilder) WithFormatter(formatter slogger.Formatter) *syslogAppenderBuilder {
+	b.formatter = formatter
+	return b
+}
+
+func (b *syslogAppenderBuilder) Build() (*SyslogAppender, error) {
+	if b.facility < KERN || b.facility > LOCAL7 {
+		return nil, fmt.Errorf("syslog_appender: invalid facility: %d", b.facility)
+	}
+
+	if b.format != RFC5424 && b.format != RFC3164 {
+		return nil, fmt.Errorf("syslog_appender: invalid format: %d", b.format)
+	}
+
+	if b.minBackoff <= 0 || b.maxBackoff < b.minBackoff {
+		return nil, fmt.Errorf("syslog_appender: invalid backoff: %v to %v", b.minBackoff, b.maxBackoff)
+	}
+
+	hostname := b.hostname
+	if hostname == "" {
+		hostname, _ = os.Hostname()
+	}
+
+	appender := &SyslogAppender{
+		network:      b.network,
+		address:      b.address,
+		format:       b.format,
+		facility:     b.facility,
+		appName:      b.appName,
+		hostname:     headerField(hostname, 255),
+		pid:          strconv.Itoa(os.Getpid()),
+		dialTimeout:  b.dialTimeout,
+		writeTimeout: b.writeTimeout,
+		minBackoff:   b.minBackoff,
+		maxBackoff:   b.maxBackoff,
+		formatter:    b.formatter,
+	}
+
+	if err := appender.connect(); err != nil {
+		return nil, err
+	}
+
+	return appender, nil
+}
+
+// Append sends log to the syslog daemon.  If sending fails, Append
+// reconnects and tries once more, unless it is backing off after a
+// failed connection attempt.
+func (self *SyslogAppender) Append(log *slogger.Log) error {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	msg := self.message(log)
+
+	if self.conn != nil {
+		if err := self.write(msg); err == nil {
+			return nil
+		}
+		self.conn.Close()
+		self.conn = nil
+	}
+
+	if err := self.connect(); err != nil {
+		return err
+	}
+
+	if err := self.write(msg); err != nil {
+		self.conn.Close()
+		self.conn = nil
+		return fmt.Errorf("syslog_appender: Failed to write to %s %s: %v", self.network, self.address, err)
+	}
+
+	return nil
+}
+
+func (self *SyslogAppender) Close() error {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	if self.conn == nil {
+		return nil
+	}
+
+	err := self.conn.Close()
+	self.conn = nil
+	return err
+}
+
+// Flush does nothing as messages are sent as they are appended.
+func (self *SyslogAppender) Flush() error {
+	return nil
+}
+
+func (self *SyslogAppender) SetFormatter(formatter slogger.Formatter) bool {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	self.formatter = formatter
+	return true
+}
+
+// connect must be called with lock held or before the appender is
+// shared.  After a failed attempt, connect fails without dialing until
+// the backoff has passed.
+func (self *SyslogAppender) connect() error {
+	now := time.Now()
+	if now.Before(self.nextDial) {
+		return fmt.Errorf("syslog_appender: Not connected to %s %s; retrying in %v", self.network, self.address, self.nextDial.Sub(now))
+	}
+
+	conn, err := self.dial()
+	if err != nil {
+		if self.backoff == 0 {
+			self.backoff = self.minBackoff
+		} else {
+			self.backoff *= 2
+			if self.backoff > self.maxBackoff {
+				self.backoff = self.maxBackoff
+			}
+		}
+		self.nextDial = now.Add(self.backoff)
+		return err
+	}
+
+	self.conn = conn
+	self.backoff = 0
+	return nil
+}
+
+func (self *SyslogAppender) dial() (net.Conn, error) {
+	if self.network != "" {
+		conn, err := net.DialTimeout(self.network, self.address, self.dialTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("syslog_appender: Failed to connect to %s %s: %v", self.network, self.address, err)
+		}
+		return conn, nil
+	}
+
+	for _, path := range localSyslogPaths {
+		if conn, err := net.DialTimeout("unixgram", path, self.dialTimeout); err == nil {
+			return conn, nil
+		}
+	}
+	return nil, fmt.Errorf("syslog_appender: No local syslog socket found in %v", localSyslogPaths)
+}
+
+// write must be called with lock held and conn set.
+func (self *SyslogAppender) write(msg []byte) error {
+	if self.writeTimeout > 0 {
+		if err := self.conn.SetWriteDeadline(time.Now().Add(self.writeTimeout)); err != nil {
+			return err
+		}
+	}
+	_, err := self.conn.Write(msg)
+	return err
+}
+
+func (self *SyslogAppender) isStream() bool {
+	switch self.network {
+	case "tcp", "tcp4", "tcp6", "unix":
+		return true
+	default:
+		return false
+	}
+}
+
+// message returns log as a syslog message, framed for the network.
+func (self *SyslogAppender) message(log *slogger.Log) []byte {
+	buf := new(bytes.Buffer)
+	priority := int(self.facility)*8 + int(SeverityForLevel(log.Level))
+
+	appName := self.appName
+	if appName == "" {
+		appName = log.Prefix
+	}
+
+	switch self.format {
+	case RFC3164:
+		fmt.Fprintf(buf, "<%d>%s %s %s[%s]: ",
+			priority,
+			log.Timestamp.Format(time.Stamp),
+			self.hostname,
+			headerField(appName, 32),
+			self.pid,
+		)
+	default:
+		fmt.Fprintf(buf, "<%d>1 %s %s %s %s - ",
+			priority,
+			log.Timestamp.Format("2006-01-02T15:04:05.000000Z07:00"),
+			self.hostname,
+			headerField(appName, 48),
+			self.pid,
+		)
+		writeStructuredData(buf, log)
+		buf.WriteByte(' ')
+	}
+
+	if _, ok := log.Preformatted(); ok || self.formatter != nil {
+		buf.WriteString(strings.TrimRight(slogger.FormatWith(self.formatter, log), "\n"))
+	} else {
+		buf.WriteString(log.Message())
+	}
+
+	if !self.isStream() {
+		return buf.Bytes()
+	}
+
+	framed := new(bytes.Buffer)
+	framed.Grow(buf.Len() + 8)
+	fmt.Fprintf(framed, "%d ", buf.Len())
+	framed.Write(buf.Bytes())
+	return framed.Bytes()
+}
+
+// writeStructuredData writes log's trace and span IDs and Context as
+// a single SD-ELEMENT, or the NILVALUE if it has none.
+func writeStructuredData(buf *bytes.Buffer, log *slogger.Log) {
+	if log.TraceID == "" && log.SpanID == "" && (log.Context == nil || log.Context.Len() == 0) {
+		buf.WriteByte('-')
+		return
+	}
+
+	buf.WriteString("[" + sdID)
+	if log.TraceID != "" {
+		writeSDParam(buf, "traceId", log.TraceID)
+	}
+	if log.SpanID != "" {
+		writeSDParam(buf, "spanId", log.SpanID)
+	}
+	log.Context.Range(func(key string, value interface{}) bool {
+		writeSDParam(buf, key, value)
+		return true
+	})
+	buf.WriteByte(']')
+}
+
+func writeSDParam(buf *bytes.Buffer, key string, value interface{}) {
+	buf.WriteByte(' ')
+	buf.WriteString(sdName(key))
+	buf.WriteString(`="`)
+	buf.WriteString(sdParamValueEscaper.Replace(fmt.Sprint(value)))
+	buf.WriteByte('"')
+}
+
+var sdParamValueEscaper = strings.NewReplacer(`"`, `\"`, `\`, `\\`, `]`, `\]`)
+
+// sdName makes key a valid SD-NAME: at most 32 printable US-ASCII
+// characters other than '=', ' ', ']' and '"'.
+func sdName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, key)
+
+	if name == "" {
+		return "_"
+	}
+	if len(name) > 32 {
+		return name[:32]
+	}
+	return name
+}
+
+// headerField makes value a valid header field of at most maxLen
+// printable US-ASCII characters, using the NILVALUE "-" if it is
+// empty.
+func headerField(value string, maxLen int) string {
+	field := strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return '_'
+		}
+		return r
+	}, value)
+
+	if field == "" {
+		return "-"
+	}
+	if len(field) > maxLen {
+		return field[:maxLen]
+	}
+	return field
+}
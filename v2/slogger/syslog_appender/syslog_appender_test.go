@@ -0,0 +1,287 @@
+// Copyright 2026 MongoDB, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syslog_appender
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mongodb/slogger/v2/slogger"
+	. "github.com/mongodb/slogger/v2/slogger/test_util"
+)
+
+func TestRFC5424OverUDP(test *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		test.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	appender, err := NewBuilder("udp", listener.LocalAddr().String()).
+		WithFacility(LOCAL3).
+		WithHostname("db1.example.com").
+		Build()
+	if err != nil {
+		test.Fatalf("Build() failed: %v", err)
+	}
+	defer appender.Close()
+
+	logger := &slogger.Logger{
+		Prefix:    "mongod",
+		Appenders: []slogger.Appender{appender},
+	}
+
+	ctxt := slogger.NewContext()
+	ctxt.Add("shard", 3)
+	ctxt.Add("query", `{"a": "]"}`)
+	_, errs := logger.LogfWithContext(slogger.WARN, "Slow query %dms", ctxt, 150)
+	AssertNoErrors(test, errs)
+
+	received := readDatagram(test, listener)
+	// LOCAL3 * 8 + WARNING == 156
+	expected := regexp.MustCompile(`^<156>1 \d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{6}(Z|[+-]\d\d:\d\d) db1\.example\.com mongod ` +
+		strconv.Itoa(os.Getpid()) + ` - \[slogger@32473 shard="3" query="\{\\"a\\": \\"\\]\\"\}"\] Slow query 150ms$`)
+	if !expected.MatchString(received) {
+		test.Errorf("Unexpected message: %s", received)
+	}
+}
+
+func TestPreformattedMessage(test *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		test.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	appender, err := NewBuilder("udp", listener.LocalAddr().String()).Build()
+	if err != nil {
+		test.Fatalf("Build() failed: %v", err)
+	}
+	defer appender.Close()
+
+	log := slogger.SimpleLog("mongod", slogger.INFO, slogger.NoErrorCode, 1, "Connection accepted")
+	log.Preformat(slogger.FormatterFunc(slogger.FormatLogfmt))
+	if err := appender.Append(log); err != nil {
+		test.Fatalf("Append failed: %v", err)
+	}
+
+	if received := readDatagram(test, listener); !strings.HasSuffix(received, ` msg="Connection accepted"`) {
+		test.Errorf("Expected the preformatted text as the MSG. Received: %s", received)
+	}
+}
+
+func TestRFC3164OverUnixgram(test *testing.T) {
+	dir, err := os.MkdirTemp("", "syslog_appender")
+	if err != nil {
+		test.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "log.sock")
+	listener, err := net.ListenPacket("unixgram", path)
+	if err != nil {
+		test.Skipf("unixgram sockets are not supported: %v", err)
+	}
+	defer listener.Close()
+
+	appender, err := NewBuilder("unixgram", path).
+		WithFormat(RFC3164).
+		WithFacility(DAEMON).
+		WithAppName("backup agent").
+		WithHostname("db1").
+		Build()
+	if err != nil {
+		test.Fatalf("Build() failed: %v", err)
+	}
+	defer appender.Close()
+
+	logger := &slogger.Logger{Appenders: []slogger.Appender{appender}}
+	_, errs := logger.Logf(slogger.ERROR, "Snapshot failed")
+	AssertNoErrors(test, errs)
+
+	received := readDatagram(test, listener)
+	// DAEMON * 8 + ERR == 27
+	expected := regexp.MustCompile(`^<27>[A-Z][a-z]{2} [ \d]\d \d\d:\d\d:\d\d db1 backup_agent\[` +
+		strconv.Itoa(os.Getpid()) + `\]: Snapshot failed$`)
+	if !expected.MatchString(received) {
+		test.Errorf("Unexpected message: %s", received)
+	}
+}
+
+func TestOctetCountingOverTCP(test *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		test.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	messages := make(chan string, 10)
+	go acceptFramedMessages(listener, messages)
+
+	appender, err := NewBuilder("tcp", listener.Addr().String()).
+		WithFormatter(slogger.FormatterFunc(slogger.FormatLogfmt)).
+		Build()
+	if err != nil {
+		test.Fatalf("Build() failed: %v", err)
+	}
+	defer appender.Close()
+
+	logger := &slogger.Logger{
+		Prefix:    "mongod",
+		Appenders: []slogger.Appender{appender},
+	}
+
+	_, errs := logger.Logf(slogger.INFO, "first\nline")
+	AssertNoErrors(test, errs)
+	_, errs = logger.Logf(slogger.DEBUG, "second")
+	AssertNoErrors(test, errs)
+
+	first := receive(test, messages)
+	if !strings.HasPrefix(first, "<14>1 ") || !strings.Contains(first, " - - time=") || !strings.HasSuffix(first, ` msg="first\nline"`) {
+		test.Errorf("Unexpected first message: %s", first)
+	}
+
+	second := receive(test, messages)
+	if !strings.HasPrefix(second, "<15>1 ") || !strings.HasSuffix(second, ` msg=second`) {
+		test.Errorf("Unexpected second message: %s", second)
+	}
+}
+
+func TestReconnectBackoff(test *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		test.Fatalf("Failed to listen: %v", err)
+	}
+	address := listener.Addr().String()
+
+	appender, err := NewBuilder("tcp", address).
+		WithBackoff(time.Hour, time.Hour).
+		Build()
+	if err != nil {
+		test.Fatalf("Build() failed: %v", err)
+	}
+	defer appender.Close()
+
+	// drop the connection while nothing is listening
+	listener.Close()
+	appender.lock.Lock()
+	appender.conn.Close()
+	appender.conn = nil
+	appender.lock.Unlock()
+
+	log := slogger.SimpleLog("mongod", slogger.INFO, slogger.NoErrorCode, 1, "message")
+	if err := appender.Append(log); err == nil || !strings.Contains(err.Error(), "Failed to connect") {
+		test.Fatalf("Expected a connection error. Received: %v", err)
+	}
+
+	listener, err = net.Listen("tcp", address)
+	if err != nil {
+		test.Fatalf("Failed to listen again: %v", err)
+	}
+	defer listener.Close()
+	messages := make(chan string, 10)
+	go acceptFramedMessages(listener, messages)
+
+	if err := appender.Append(log); err == nil || !strings.Contains(err.Error(), "retrying in") {
+		test.Errorf("Expected Append not to reconnect while backing off. Received: %v", err)
+	}
+
+	appender.lock.Lock()
+	appender.nextDial = time.Time{}
+	appender.lock.Unlock()
+
+	if err := appender.Append(log); err != nil {
+		test.Fatalf("Expected Append to reconnect after backing off. Received: %v", err)
+	}
+	if message := receive(test, messages); !strings.HasSuffix(message, " message") {
+		test.Errorf("Unexpected message: %s", message)
+	}
+}
+
+func TestSeverityForLevel(test *testing.T) {
+	expected := map[slogger.Level]Severity{
+		slogger.TRACE: DEBUG,
+		slogger.DEBUG: DEBUG,
+		slogger.INFO:  INFO,
+		slogger.WARN:  WARNING,
+		slogger.ERROR: ERR,
+		slogger.FATAL: CRIT,
+	}
+
+	for level, severity := range expected {
+		if SeverityForLevel(level) != severity {
+			test.Errorf("Expected %v to map to %d. Received: %d", level, severity, SeverityForLevel(level))
+		}
+	}
+}
+
+func readDatagram(test *testing.T, listener net.PacketConn) string {
+	buf := make([]byte, 4096)
+	listener.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := listener.ReadFrom(buf)
+	if err != nil {
+		test.Fatalf("Failed to read datagram: %v", err)
+	}
+	return string(buf[:n])
+}
+
+// acceptFramedMessages reads octet counted messages from the first
+// connection accepted by listener.
+func acceptFramedMessages(listener net.Listener, messages chan<- string) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	for {
+		lenStr, err := reader.ReadString(' ')
+		if err != nil {
+			return
+		}
+
+		length, err := strconv.Atoi(strings.TrimSpace(lenStr))
+		if err != nil {
+			messages <- fmt.Sprintf("bad frame length: %q", lenStr)
+			return
+		}
+
+		msg := make([]byte, length)
+		if _, err := io.ReadFull(reader, msg); err != nil {
+			return
+		}
+		messages <- string(msg)
+	}
+}
+
+func receive(test *testing.T, messages <-chan string) string {
+	select {
+	case msg := <-messages:
+		return msg
+	case <-time.After(5 * time.Second):
+		test.Fatal("Timed out waiting for a message")
+		return ""
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2026 MongoDB, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slogger
+
+import "context"
+
+// A TraceExtractor returns the IDs of the trace and span carried by
+// ctx, or empty strings if there are none.
+type TraceExtractor func(ctx context.Context) (traceID, spanID string)
+
+var traceExtractor TraceExtractor
+
+// SetTraceExtractor sets the function used to fill in Log.TraceID and
+// Log.SpanID from the context.Context passed to methods such as
+// Logger.LogfCtx.  It is nil, and Logs carry no trace, by default.
+// Example, using OpenTelemetry:
+//
+//	slogger.SetTraceExtractor(func(ctx context.Context) (string, string) {
+//	    spanCtx := trace.SpanContextFromContext(ctx)
+//	    if !spanCtx.IsValid() {
+//	        return "", ""
+//	    }
+//	    return spanCtx.TraceID().String(), spanCtx.SpanID().String()
+//	})
+func SetTraceExtractor(extractor TraceExtractor) {
+	loggerConfigLock.Lock()
+	defer loggerConfigLock.Unlock()
+	traceExtractor = extractor
+}
+
+func GetTraceExtractor() TraceExtractor {
+	loggerConfigLock.RLock()
+	defer loggerConfigLock.RUnlock()
+	return traceExtractor
+}
+
+// ExtractTrace returns the trace and span IDs for ctx using the
+// function passed to SetTraceExtractor.  ctx may be nil.
+func ExtractTrace(ctx context.Context) (traceID, spanID string) {
+	extractor := GetTraceExtractor()
+	if ctx == nil || extractor == nil {
+		return "", ""
+	}
+	return extractor(ctx)
+}